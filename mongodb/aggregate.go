@@ -0,0 +1,93 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// accumulators maps the aggregate metrics computed from the field value to their MongoDB accumulator.
+var accumulators = map[filter.AggregateMetric]string{
+	filter.AggregateMetricSum: "$sum",
+	filter.AggregateMetricMin: "$min",
+	filter.AggregateMetricMax: "$max",
+	filter.AggregateMetricAvg: "$avg",
+}
+
+// BuildAggregationPipeline translates a query.AggregateSelector into a MongoDB aggregation pipeline,
+// using fieldMapping to map request field names to document fields.
+// The pipeline outputs one document per group, shaped like query.AggregateResult and ordered by group values.
+// The filter is translated with the rating scales of queryOptions, if given; see BuildMongoFilter.
+// An errs.InvalidRequestError is returned if the selector cannot be translated.
+func BuildAggregationPipeline(aggregateSelector query.AggregateSelector, fieldMapping map[string]string, queryOptions ...query.Options) (mongo.Pipeline, error) {
+	aggregation := aggregateSelector.Aggregation
+	if err := aggregation.Validate(); err != nil {
+		return nil, err
+	}
+
+	mongoFilter, err := BuildMongoFilter(aggregateSelector.Filter, fieldMapping, queryOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := mongo.Pipeline{}
+	if len(mongoFilter) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: mongoFilter}})
+	}
+
+	var groupID any
+	if len(aggregation.GroupBy) > 0 {
+		groupKeys := bson.D{}
+		for _, name := range aggregation.GroupBy {
+			groupKeys = append(groupKeys, bson.E{Key: name, Value: "$" + mappedFieldName(name, fieldMapping)})
+		}
+		groupID = groupKeys
+	}
+
+	field := "$" + mappedFieldName(aggregation.Field, fieldMapping)
+	var accumulator bson.M
+	if aggregation.Metric == filter.AggregateMetricValueCount {
+		// count the documents where the field holds a value
+		accumulator = bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{field, nil}}, nil}}, 0, 1}}}
+	} else {
+		accumulator = bson.M{accumulators[aggregation.Metric]: field}
+	}
+
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: groupID}, {Key: "value", Value: accumulator}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		bson.D{{Key: "$project", Value: bson.D{{Key: "_id", Value: 0}, {Key: "group", Value: "$_id"}, {Key: "value", Value: 1}}}},
+	)
+
+	return pipeline, nil
+}
+
+// AggregateWithMetadata runs the aggregation described by aggregateSelector against collection
+// and returns its results together with the metadata echoing the filter and aggregation.
+// The filter is translated with the rating scales of queryOptions, if given.
+func AggregateWithMetadata(ctx context.Context, collection *mongo.Collection, aggregateSelector query.AggregateSelector, fieldMapping map[string]string, queryOptions ...query.Options) (query.AggregateResponse, error) {
+	response := query.AggregateResponse{
+		Metadata: query.NewAggregateMetadata(aggregateSelector),
+		Data:     make([]query.AggregateResult, 0),
+	}
+
+	pipeline, err := BuildAggregationPipeline(aggregateSelector, fieldMapping, queryOptions...)
+	if err != nil {
+		return response, err
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return response, fmt.Errorf("failed to aggregate documents in collection %s: %w", collection.Name(), err)
+	}
+
+	if err := cur.All(ctx, &response.Data); err != nil {
+		return response, fmt.Errorf("failed to decode aggregation results from collection %s: %w", collection.Name(), err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestBuildAggregationPipeline(t *testing.T) {
+	// the stages following $group are the same for every aggregation
+	tail := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.D{{Key: "_id", Value: 0}, {Key: "group", Value: "$_id"}, {Key: "value", Value: 1}}}},
+	}
+	group := func(id any, accumulator bson.M) bson.D {
+		return bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: id}, {Key: "value", Value: accumulator}}}}
+	}
+
+	tests := []struct {
+		name         string
+		selector     query.AggregateSelector
+		fieldMapping map[string]string
+		want         mongo.Pipeline
+		wantErr      bool
+	}{
+		{
+			name:     "sum without filter or grouping",
+			selector: query.AggregateSelector{Aggregation: filter.Aggregation{Metric: filter.AggregateMetricSum, Field: "amount"}},
+			want:     append(mongo.Pipeline{group(nil, bson.M{"$sum": "$amount"})}, tail...),
+		},
+		{
+			name: "filtered and grouped average on mapped fields",
+			selector: query.AggregateSelector{
+				Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+					{Name: "status", Operator: filter.CompareOperatorIsEqualTo, Value: "paid"},
+				}},
+				Aggregation: filter.Aggregation{Metric: filter.AggregateMetricAvg, Field: "amount", GroupBy: []string{"city", "status"}},
+			},
+			fieldMapping: map[string]string{"amount": "order.amount", "city": "address.city"},
+			want: append(mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"status": "paid"}}},
+				group(bson.D{{Key: "city", Value: "$address.city"}, {Key: "status", Value: "$status"}}, bson.M{"$avg": "$order.amount"}),
+			}, tail...),
+		},
+		{
+			name:     "value count",
+			selector: query.AggregateSelector{Aggregation: filter.Aggregation{Metric: filter.AggregateMetricValueCount, Field: "email"}},
+			want: append(mongo.Pipeline{
+				group(nil, bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$email", nil}}, nil}}, 0, 1}}}),
+			}, tail...),
+		},
+		{
+			name:     "operator field",
+			selector: query.AggregateSelector{Aggregation: filter.Aggregation{Metric: filter.AggregateMetricSum, Field: "$where"}},
+			wantErr:  true,
+		},
+		{
+			name:     "nested group by",
+			selector: query.AggregateSelector{Aggregation: filter.Aggregation{Metric: filter.AggregateMetricSum, Field: "amount", GroupBy: []string{"address.city"}}},
+			wantErr:  true,
+		},
+		{
+			name: "invalid filter",
+			selector: query.AggregateSelector{
+				Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+					{Name: "$where", Operator: filter.CompareOperatorIsEqualTo, Value: "1"},
+				}},
+				Aggregation: filter.Aggregation{Metric: filter.AggregateMetricSum, Field: "amount"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildAggregationPipeline(tt.selector, tt.fieldMapping)
+			if tt.wantErr {
+				var response *errs.Response
+				if !errors.As(err, &response) || response.ErrorCode != errs.InvalidRequestError {
+					t.Fatalf("BuildAggregationPipeline() = %v, %v, want an InvalidRequestError", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildAggregationPipeline() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildAggregationPipeline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// DefaultAutocompleteLimit is the number of suggestions returned when no limit is given.
+	DefaultAutocompleteLimit = 10
+	// MaxAutocompleteLimit is the largest number of suggestions returned by Autocomplete.
+	MaxAutocompleteLimit = 50
+)
+
+// autocompleteCollation compares strings ignoring case. An index on the autocompleted field created with the same
+// collation lets the prefix range be answered from the index.
+var autocompleteCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// Autocomplete returns up to limit distinct values of the field described by option that start with prefix, ignoring case,
+// in alphabetical order. Array fields are searched element by element.
+// The prefix is matched as a string range under a case-insensitive collation rather than a regular expression,
+// so it uses an index on the field created with the collation {locale: "en", strength: 2}.
+// An errs.InvalidRequestError is returned if option is not of the autocomplete control type.
+func Autocomplete(ctx context.Context, collection *mongo.Collection, option filter.RequestOption, prefix string, limit int, fieldMapping map[string]string) ([]filter.ReadableValue[string], error) {
+	if option.Control.Type != filter.ControlTypeAutocomplete {
+		return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("filter option %q is not an autocomplete option", option.Name.Value))
+	}
+
+	if limit < 1 {
+		limit = DefaultAutocompleteLimit
+	}
+	limit = min(limit, MaxAutocompleteLimit)
+
+	fieldName := mappedFieldName(option.Name.Value, fieldMapping)
+
+	// U+FFFF sorts after every character, so the range holds every string starting with prefix
+	match := bson.M{fieldName: bson.M{"$type": "string"}}
+	if prefix != "" {
+		match = bson.M{fieldName: bson.M{"$gte": prefix, "$lt": prefix + "\uffff"}}
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$unwind", Value: "$" + fieldName}},
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$" + fieldName}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	}
+
+	cur, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetCollation(autocompleteCollation))
+	if err != nil {
+		return nil, fmt.Errorf("failed to autocomplete %s in collection %s: %w", fieldName, collection.Name(), err)
+	}
+
+	var results []struct {
+		Value string `bson:"_id"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode autocomplete values from collection %s: %w", collection.Name(), err)
+	}
+
+	values := make([]filter.ReadableValue[string], len(results))
+	for i, result := range results {
+		values[i] = filter.ReadableValue[string]{Label: result.Value, Value: result.Value}
+	}
+
+	return values, nil
+}
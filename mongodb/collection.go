@@ -0,0 +1,50 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// FindWithMetadata runs the query described by resultSelector against collection and returns the page of documents
+// together with its metadata. The number of matching documents is counted in the same call, so the paging metadata
+// holds the total, displayable and page counts as well as whether further pages exist.
+// Pages beyond the displayable results cap are returned empty. Paging is validated and capped against the limits of queryOptions,
+// if given, or paging.DefaultLimits.
+func FindWithMetadata[T any](ctx context.Context, collection *mongo.Collection, resultSelector query.ResultSelector, fieldMapping map[string]string, queryOptions ...query.Options) (query.ResponseListWithMetadata[T], error) {
+	var response query.ResponseListWithMetadata[T]
+
+	mongoFilter, opts, err := BuildFindOptions(resultSelector, fieldMapping, queryOptions...)
+	if err != nil {
+		return response, err
+	}
+
+	totalResults, err := collection.CountDocuments(ctx, mongoFilter)
+	if err != nil {
+		return response, fmt.Errorf("failed to count documents in collection %s: %w", collection.Name(), err)
+	}
+
+	response.Metadata = query.NewMetadata(resultSelector, uint64(totalResults), queryOptions...)
+	response.Data = make([]T, 0)
+
+	if response.Metadata.Paging != nil {
+		displayable := int64(response.Metadata.Paging.TotalDisplayableResults)
+		if *opts.Skip >= displayable {
+			return response, nil
+		}
+		opts.SetLimit(min(*opts.Limit, displayable-*opts.Skip))
+	}
+
+	cur, err := collection.Find(ctx, mongoFilter, opts)
+	if err != nil {
+		return response, fmt.Errorf("failed to find documents in collection %s: %w", collection.Name(), err)
+	}
+
+	if err := cur.All(ctx, &response.Data); err != nil {
+		return response, fmt.Errorf("failed to decode documents from collection %s: %w", collection.Name(), err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,261 @@
+package mongodb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cursor is the decoded form of a paging cursor.
+// Values holds the sort key values of the document the cursor was built from, in sort order.
+// Backward is set for cursors pointing to the documents preceding that document.
+type cursor struct {
+	Values   bson.D `bson:"v"`
+	Backward bool   `bson:"b"`
+}
+
+// BuildCursorFindOptions translates a query.ResultSelector into a MongoDB filter and find options for cursor (keyset) pagination.
+//
+// When the paging request carries a cursor, the filter is narrowed to the documents located after (or, for a previous cursor,
+// before) the document the cursor was built from according to the active sorting, which always ends with _id.
+// One more document than the page size is requested so that NewCursorPage can tell whether another page exists.
+// Cursors are signed with secret when it is not empty; an errs.InvalidPageRequestError is returned for cursors that
+// were tampered with or built for a different sorting, and for page sizes exceeding the limits of queryOptions, if given,
+// or paging.DefaultLimits.
+func BuildCursorFindOptions(resultSelector query.ResultSelector, fieldMapping map[string]string, secret []byte, queryOptions ...query.Options) (bson.M, *options.FindOptions, error) {
+	mongoFilter, err := BuildMongoFilter(resultSelector.Filter, fieldMapping, queryOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort, err := buildSort(resultSelector.Sorting, fieldMapping)
+	if err != nil {
+		return nil, nil, err
+	}
+	if usesTextScore(sort) {
+		return nil, nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("sorting by %s is not supported with cursor pagination", sorting.TextScoreColumn))
+	}
+
+	if err := resultSelector.Paging.Validate(query.FirstOptions(queryOptions).PagingLimits()); err != nil {
+		return nil, nil, err
+	}
+
+	limit := paging.DefaultPageSize
+	if resultSelector.Paging != nil {
+		limit = resultSelector.Paging.Limit()
+
+		if resultSelector.Paging.Cursor != "" {
+			c, err := decodeCursor(resultSelector.Paging.Cursor, secret)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			predicate, err := keysetPredicate(sort, c)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			mongoFilter = andQuery([]bson.M{mongoFilter, predicate})
+			if c.Backward {
+				sort = reverseSort(sort)
+			}
+		}
+	}
+
+	opts := options.Find().SetSort(sort).SetLimit(int64(limit + 1))
+	return mongoFilter, opts, nil
+}
+
+// NewCursorPage turns the documents found with the options of BuildCursorFindOptions into a page.
+// It drops the extra document used to detect further pages, restores the sort order of pages read backward
+// and returns the paging response holding the cursors to the next and previous pages.
+func NewCursorPage[T any](documents []T, resultSelector query.ResultSelector, fieldMapping map[string]string, secret []byte) ([]T, *paging.Response, error) {
+	sort, err := buildSort(resultSelector.Sorting, fieldMapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	limit := paging.DefaultPageSize
+	var current *cursor
+	if resultSelector.Paging != nil {
+		limit = resultSelector.Paging.Limit()
+
+		if resultSelector.Paging.Cursor != "" {
+			if current, err = decodeCursor(resultSelector.Paging.Cursor, secret); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	hasMore := len(documents) > limit
+	if hasMore {
+		documents = documents[:limit]
+	}
+
+	backward := current != nil && current.Backward
+	if backward {
+		slices.Reverse(documents)
+	}
+
+	response := &paging.Response{PageSize: limit}
+	if len(documents) == 0 {
+		return documents, response, nil
+	}
+
+	// moving forward there is a next page if more documents were found, and a previous one unless this is the first page;
+	// moving backward it is the other way around
+	hasNext, hasPrevious := hasMore, current != nil
+	if backward {
+		hasNext, hasPrevious = true, hasMore
+	}
+
+	if hasNext {
+		if response.NextCursor, err = documentCursor(documents[len(documents)-1], sort, false, secret); err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasPrevious {
+		if response.PreviousCursor, err = documentCursor(documents[0], sort, true, secret); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return documents, response, nil
+}
+
+// keysetPredicate returns the filter selecting the documents after the cursor position for the given sort.
+// For a sort on (a asc, b desc, _id desc) it matches a > va, or a = va and b < vb, or a = va and b = vb and _id < vid.
+//
+// MongoDB sorts null and missing values alike, before any other value. A condition moving towards lower values
+// therefore also matches documents where the field is null or missing, a condition moving towards higher values from null
+// matches every document where it is set, and nothing is lower than null. Equality with null matches missing fields too.
+func keysetPredicate(sort bson.D, c *cursor) (bson.M, error) {
+	if len(c.Values) != len(sort) {
+		return nil, errs.Body(errs.InvalidPageRequestError, errors.New("cursor does not match the sorting"))
+	}
+
+	conditions := make([]bson.M, 0, len(sort))
+	for i, key := range sort {
+		if c.Values[i].Key != key.Key {
+			return nil, errs.Body(errs.InvalidPageRequestError, errors.New("cursor does not match the sorting"))
+		}
+
+		descending := (key.Value == -1) != c.Backward
+		value := c.Values[i].Value
+
+		var condition bson.M
+		switch {
+		case value == nil && descending:
+			// no value sorts before null
+			continue
+		case value == nil:
+			condition = bson.M{key.Key: bson.M{"$ne": nil}}
+		case descending && key.Key == idField:
+			// _id is never null nor missing
+			condition = bson.M{key.Key: bson.M{"$lt": value}}
+		case descending:
+			condition = bson.M{"$or": []bson.M{{key.Key: bson.M{"$lt": value}}, {key.Key: nil}}}
+		default:
+			condition = bson.M{key.Key: bson.M{"$gt": value}}
+		}
+
+		for _, previous := range c.Values[:i] {
+			condition[previous.Key] = previous.Value
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) == 0 {
+		return matchNothing(), nil
+	}
+	return bson.M{"$or": conditions}, nil
+}
+
+// reverseSort returns the sort with every direction flipped.
+func reverseSort(sort bson.D) bson.D {
+	reversed := make(bson.D, len(sort))
+	for i, key := range sort {
+		reversed[i] = bson.E{Key: key.Key, Value: -key.Value.(int)}
+	}
+	return reversed
+}
+
+// documentCursor builds the cursor pointing after (or before, when backward is set) the given document.
+// Sort fields missing from the document, such as nil pointers marked omitempty, are stored as null, which MongoDB sorts them as.
+func documentCursor(document any, sort bson.D, backward bool, secret []byte) (string, error) {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal document for cursor: %w", err)
+	}
+
+	c := cursor{Values: make(bson.D, len(sort)), Backward: backward}
+	for i, key := range sort {
+		// the document was just marshalled, so a failed lookup means that the field is missing
+		value, err := bson.Raw(raw).LookupErr(strings.Split(key.Key, ".")...)
+		if err != nil {
+			c.Values[i] = bson.E{Key: key.Key, Value: nil}
+			continue
+		}
+		c.Values[i] = bson.E{Key: key.Key, Value: value}
+	}
+
+	return encodeCursor(c, secret)
+}
+
+// encodeCursor serializes a cursor to an URL safe string, followed by its signature when secret is set.
+func encodeCursor(c cursor, secret []byte) (string, error) {
+	payload, err := bson.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(payload)
+	if len(secret) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(sign(payload, secret))
+	}
+	return token, nil
+}
+
+// decodeCursor parses a cursor produced by encodeCursor, verifying its signature when secret is set.
+func decodeCursor(token string, secret []byte) (*cursor, error) {
+	encodedPayload, encodedSignature, signed := strings.Cut(token, ".")
+	if signed != (len(secret) > 0) {
+		return nil, errs.Body(errs.InvalidPageRequestError, errors.New("invalid cursor"))
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, errs.Body(errs.InvalidPageRequestError, fmt.Errorf("invalid cursor: %w", err))
+	}
+
+	if signed {
+		signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+		if err != nil || !hmac.Equal(signature, sign(payload, secret)) {
+			return nil, errs.Body(errs.InvalidPageRequestError, errors.New("invalid cursor signature"))
+		}
+	}
+
+	var c cursor
+	if err := bson.Unmarshal(payload, &c); err != nil {
+		return nil, errs.Body(errs.InvalidPageRequestError, fmt.Errorf("invalid cursor: %w", err))
+	}
+	return &c, nil
+}
+
+// sign returns the HMAC-SHA256 of payload keyed with secret.
+func sign(payload, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
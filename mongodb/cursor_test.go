@@ -0,0 +1,233 @@
+package mongodb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type cursorTestDocument struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Name  *string            `bson:"name,omitempty"`
+	Price int                `bson:"price"`
+}
+
+func TestDocumentCursorRoundTrip(t *testing.T) {
+	id := primitive.NewObjectID()
+	name := "shoes"
+	secret := []byte("secret")
+
+	tests := []struct {
+		name     string
+		document cursorTestDocument
+		sort     bson.D
+		backward bool
+		want     bson.D
+	}{
+		{
+			name:     "set fields",
+			document: cursorTestDocument{ID: id, Name: &name, Price: 20},
+			sort:     bson.D{{Key: "name", Value: 1}, {Key: "price", Value: -1}, {Key: idField, Value: -1}},
+			want:     bson.D{{Key: "name", Value: "shoes"}, {Key: "price", Value: int32(20)}, {Key: idField, Value: id}},
+		},
+		{
+			name:     "missing field stored as null",
+			document: cursorTestDocument{ID: id, Price: 20},
+			sort:     bson.D{{Key: "name", Value: 1}, {Key: idField, Value: 1}},
+			backward: true,
+			want:     bson.D{{Key: "name", Value: nil}, {Key: idField, Value: id}},
+		},
+		{
+			name:     "missing nested field stored as null",
+			document: cursorTestDocument{ID: id},
+			sort:     bson.D{{Key: "name.first", Value: 1}, {Key: idField, Value: 1}},
+			want:     bson.D{{Key: "name.first", Value: nil}, {Key: idField, Value: id}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := documentCursor(tt.document, tt.sort, tt.backward, secret)
+			if err != nil {
+				t.Fatalf("documentCursor() error = %v", err)
+			}
+
+			c, err := decodeCursor(token, secret)
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			if !reflect.DeepEqual(c.Values, tt.want) || c.Backward != tt.backward {
+				t.Errorf("decodeCursor() = %v (backward %t), want %v (backward %t)", c.Values, c.Backward, tt.want, tt.backward)
+			}
+
+			if _, err := keysetPredicate(tt.sort, c); err != nil {
+				t.Errorf("keysetPredicate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsTamperedCursors(t *testing.T) {
+	secret := []byte("secret")
+	token, err := encodeCursor(cursor{Values: bson.D{{Key: idField, Value: 1}}}, secret)
+	if err != nil {
+		t.Fatalf("encodeCursor() error = %v", err)
+	}
+
+	unsigned, err := encodeCursor(cursor{Values: bson.D{{Key: idField, Value: 2}}}, nil)
+	if err != nil {
+		t.Fatalf("encodeCursor() error = %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		token  string
+		secret []byte
+	}{
+		"wrong secret":         {token: token, secret: []byte("other")},
+		"missing signature":    {token: unsigned, secret: secret},
+		"unexpected signature": {token: token, secret: nil},
+		"forged payload":       {token: unsigned + token[len(token)-44:], secret: secret},
+		"not base64":           {token: "!!!", secret: nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := decodeCursor(tc.token, tc.secret); err == nil {
+				t.Error("decodeCursor() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestKeysetPredicate(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		sort    bson.D
+		cursor  cursor
+		want    bson.M
+		wantErr bool
+	}{
+		{
+			name:   "ascending",
+			sort:   bson.D{{Key: "name", Value: 1}, {Key: idField, Value: 1}},
+			cursor: cursor{Values: bson.D{{Key: "name", Value: "b"}, {Key: idField, Value: id}}},
+			want: bson.M{"$or": []bson.M{
+				{"name": bson.M{"$gt": "b"}},
+				{"name": "b", idField: bson.M{"$gt": id}},
+			}},
+		},
+		{
+			name:   "descending includes null and missing values",
+			sort:   bson.D{{Key: "name", Value: -1}, {Key: idField, Value: -1}},
+			cursor: cursor{Values: bson.D{{Key: "name", Value: "b"}, {Key: idField, Value: id}}},
+			want: bson.M{"$or": []bson.M{
+				{"$or": []bson.M{{"name": bson.M{"$lt": "b"}}, {"name": nil}}},
+				{"name": "b", idField: bson.M{"$lt": id}},
+			}},
+		},
+		{
+			name:   "ascending from null",
+			sort:   bson.D{{Key: "name", Value: 1}, {Key: idField, Value: 1}},
+			cursor: cursor{Values: bson.D{{Key: "name", Value: nil}, {Key: idField, Value: id}}},
+			want: bson.M{"$or": []bson.M{
+				{"name": bson.M{"$ne": nil}},
+				{"name": nil, idField: bson.M{"$gt": id}},
+			}},
+		},
+		{
+			name:   "descending from null",
+			sort:   bson.D{{Key: "name", Value: -1}, {Key: idField, Value: -1}},
+			cursor: cursor{Values: bson.D{{Key: "name", Value: nil}, {Key: idField, Value: id}}},
+			want: bson.M{"$or": []bson.M{
+				{"name": nil, idField: bson.M{"$lt": id}},
+			}},
+		},
+		{
+			name:   "backward from null",
+			sort:   bson.D{{Key: "name", Value: 1}, {Key: idField, Value: 1}},
+			cursor: cursor{Values: bson.D{{Key: "name", Value: nil}, {Key: idField, Value: id}}, Backward: true},
+			want: bson.M{"$or": []bson.M{
+				{"name": nil, idField: bson.M{"$lt": id}},
+			}},
+		},
+		{
+			name:   "nothing before null",
+			sort:   bson.D{{Key: "name", Value: -1}},
+			cursor: cursor{Values: bson.D{{Key: "name", Value: nil}}},
+			want:   matchNothing(),
+		},
+		{
+			name:    "other sorting",
+			sort:    bson.D{{Key: "price", Value: 1}, {Key: idField, Value: 1}},
+			cursor:  cursor{Values: bson.D{{Key: "name", Value: "b"}, {Key: idField, Value: id}}},
+			wantErr: true,
+		},
+		{
+			name:    "other number of keys",
+			sort:    bson.D{{Key: idField, Value: 1}},
+			cursor:  cursor{Values: bson.D{{Key: "name", Value: "b"}, {Key: idField, Value: id}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keysetPredicate(tt.sort, &tt.cursor)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("keysetPredicate() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("keysetPredicate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keysetPredicate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	name := "shoes"
+	documents := []cursorTestDocument{
+		{ID: primitive.NewObjectID()},
+		{ID: primitive.NewObjectID(), Name: &name},
+		{ID: primitive.NewObjectID(), Name: &name},
+	}
+	selector := query.ResultSelector{
+		Sorting: &sorting.Request{Keys: []sorting.Key{{Column: "name", Direction: sorting.DirectionAscending}}},
+		Paging:  &paging.Request{PageSize: 2},
+	}
+
+	page, response, err := NewCursorPage(documents, selector, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCursorPage() error = %v", err)
+	}
+	if len(page) != 2 || response.NextCursor == "" || response.PreviousCursor != "" {
+		t.Fatalf("NewCursorPage() = %d documents, next %q, previous %q, want 2 documents and a next cursor only", len(page), response.NextCursor, response.PreviousCursor)
+	}
+
+	selector.Paging.Cursor = response.NextCursor
+	mongoFilter, opts, err := BuildCursorFindOptions(selector, nil, nil)
+	if err != nil {
+		t.Fatalf("BuildCursorFindOptions() error = %v", err)
+	}
+
+	want := bson.M{"$or": []bson.M{
+		{"name": bson.M{"$gt": "shoes"}},
+		{"name": "shoes", idField: bson.M{"$gt": documents[1].ID}},
+	}}
+	if !reflect.DeepEqual(mongoFilter, want) {
+		t.Errorf("BuildCursorFindOptions() filter = %v, want %v", mongoFilter, want)
+	}
+	if opts.Limit == nil || *opts.Limit != 3 {
+		t.Errorf("BuildCursorFindOptions() limit = %v, want 3", opts.Limit)
+	}
+}
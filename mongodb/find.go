@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// idField is the MongoDB primary key, used as the sort tiebreaker.
+	idField = "_id"
+	// textScoreField is the field the text search score is projected to when sorting by sorting.TextScoreColumn.
+	textScoreField = "textScore"
+)
+
+// BuildFindOptions translates a query.ResultSelector into a MongoDB filter and the find options
+// applying its sorting and paging, using fieldMapping to map request field names to document fields.
+//
+// Results are always ordered by _id after the requested sort columns, so documents with equal
+// sort values keep a stable order from one page to the next. Paging uses a zero-based page index.
+// Sorting by sorting.TextScoreColumn orders by text search relevance and adds the score to the documents as textScore.
+// An errs.InvalidRequestError is returned if the selector cannot be translated, and an errs.InvalidPageRequestError
+// if the paging request exceeds the limits of queryOptions, if given, or paging.DefaultLimits.
+func BuildFindOptions(resultSelector query.ResultSelector, fieldMapping map[string]string, queryOptions ...query.Options) (bson.M, *options.FindOptions, error) {
+	mongoFilter, err := BuildMongoFilter(resultSelector.Filter, fieldMapping, queryOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort, err := buildSort(resultSelector.Sorting, fieldMapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := resultSelector.Paging.Validate(query.FirstOptions(queryOptions).PagingLimits()); err != nil {
+		return nil, nil, err
+	}
+
+	opts := options.Find().SetSort(sort)
+	if usesTextScore(sort) {
+		if len(resultSelector.Filter.FieldsWithOperator(filter.CompareOperatorTextContains)) == 0 {
+			return nil, nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("sorting by %s requires a %s filter", sorting.TextScoreColumn, filter.CompareOperatorTextContains))
+		}
+		opts.SetProjection(bson.M{textScoreField: bson.M{"$meta": "textScore"}})
+	}
+	if resultSelector.Paging != nil {
+		opts.SetSkip(int64(resultSelector.Paging.Offset()))
+		opts.SetLimit(int64(resultSelector.Paging.Limit()))
+	}
+
+	return mongoFilter, opts, nil
+}
+
+// buildSort translates a sorting request into a MongoDB sort document ending with the _id tiebreaker,
+// which follows the direction of the last sort key.
+func buildSort(sortingRequest *sorting.Request, fieldMapping map[string]string) (bson.D, error) {
+	sort := bson.D{}
+	tiebreaker := 1
+
+	for _, key := range sortingRequest.SortKeys() {
+		if key.Column == sorting.TextScoreColumn {
+			sort = append(sort, bson.E{Key: textScoreField, Value: bson.M{"$meta": "textScore"}})
+			tiebreaker = -1
+			continue
+		}
+
+		if key.Column == "" || strings.HasPrefix(key.Column, "$") {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("sort column %q is not allowed", key.Column))
+		}
+
+		order, err := sortOrder(key.Direction)
+		if err != nil {
+			return nil, err
+		}
+
+		fieldName := mappedFieldName(key.Column, fieldMapping)
+		sort = append(sort, bson.E{Key: fieldName, Value: order})
+		tiebreaker = order
+		// _id is unique, any further key would never be used
+		if fieldName == idField {
+			return sort, nil
+		}
+	}
+
+	return append(sort, bson.E{Key: idField, Value: tiebreaker}), nil
+}
+
+// usesTextScore reports whether the sort orders by text search relevance.
+func usesTextScore(sort bson.D) bool {
+	for _, key := range sort {
+		if _, ok := key.Value.(bson.M); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sortOrder returns the MongoDB sort order for a direction, ascending when no direction is given.
+func sortOrder(direction sorting.SortDirection) (int, error) {
+	switch sorting.SortDirectionFromString(string(direction)) {
+	case sorting.DirectionDescending:
+		return -1, nil
+	case sorting.DirectionAscending:
+		return 1, nil
+	default:
+		if direction != sorting.NoDirection {
+			return 0, errs.Body(errs.InvalidRequestError, fmt.Errorf("sort direction %q is not supported", direction))
+		}
+		return 1, nil
+	}
+}
@@ -0,0 +1,163 @@
+package mongodb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBuildSort(t *testing.T) {
+	textScore := bson.E{Key: textScoreField, Value: bson.M{"$meta": "textScore"}}
+
+	tests := []struct {
+		name         string
+		request      *sorting.Request
+		fieldMapping map[string]string
+		want         bson.D
+		wantErr      bool
+	}{
+		{name: "no sorting", request: nil, want: bson.D{{Key: idField, Value: 1}}},
+		{
+			name:    "tiebreaker follows the last key",
+			request: &sorting.Request{Keys: []sorting.Key{{Column: "name", Direction: sorting.DirectionAscending}, {Column: "price", Direction: sorting.DirectionDescending}}},
+			want:    bson.D{{Key: "name", Value: 1}, {Key: "price", Value: -1}, {Key: idField, Value: -1}},
+		},
+		{
+			name:    "default and upper case directions",
+			request: &sorting.Request{Keys: []sorting.Key{{Column: "name"}, {Column: "price", Direction: "DESC"}}},
+			want:    bson.D{{Key: "name", Value: 1}, {Key: "price", Value: -1}, {Key: idField, Value: -1}},
+		},
+		{
+			name:    "legacy single column",
+			request: &sorting.Request{SortColumn: "price", SortDirection: sorting.DirectionDescending},
+			want:    bson.D{{Key: "price", Value: -1}, {Key: idField, Value: -1}},
+		},
+		{
+			name:         "mapped column",
+			request:      &sorting.Request{Keys: []sorting.Key{{Column: "city", Direction: sorting.DirectionAscending}}},
+			fieldMapping: map[string]string{"city": "address.city"},
+			want:         bson.D{{Key: "address.city", Value: 1}, {Key: idField, Value: 1}},
+		},
+		{
+			name:    "keys after _id dropped",
+			request: &sorting.Request{Keys: []sorting.Key{{Column: idField, Direction: sorting.DirectionDescending}, {Column: "name"}}},
+			want:    bson.D{{Key: idField, Value: -1}},
+		},
+		{
+			name:    "text score",
+			request: &sorting.Request{Keys: []sorting.Key{{Column: sorting.TextScoreColumn}}},
+			want:    bson.D{textScore, {Key: idField, Value: -1}},
+		},
+		{name: "empty column", request: &sorting.Request{Keys: []sorting.Key{{Column: ""}}}, wantErr: true},
+		{name: "operator column", request: &sorting.Request{Keys: []sorting.Key{{Column: "$where"}}}, wantErr: true},
+		{name: "unknown direction", request: &sorting.Request{Keys: []sorting.Key{{Column: "name", Direction: "up"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildSort(tt.request, tt.fieldMapping)
+			if tt.wantErr {
+				var response *errs.Response
+				if !errors.As(err, &response) || response.ErrorCode != errs.InvalidRequestError {
+					t.Fatalf("buildSort() = %v, %v, want an InvalidRequestError", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildSort() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildSort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFindOptions(t *testing.T) {
+	selector := query.ResultSelector{
+		Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+			{Name: "status", Operator: filter.CompareOperatorIsEqualTo, Value: "active"},
+		}},
+		Sorting: &sorting.Request{Keys: []sorting.Key{{Column: "price", Direction: sorting.DirectionDescending}}},
+		Paging:  &paging.Request{PageIndex: 2, PageSize: 20},
+	}
+
+	mongoFilter, opts, err := BuildFindOptions(selector, nil)
+	if err != nil {
+		t.Fatalf("BuildFindOptions() error = %v", err)
+	}
+	if want := (bson.M{"status": "active"}); !reflect.DeepEqual(mongoFilter, want) {
+		t.Errorf("BuildFindOptions() filter = %v, want %v", mongoFilter, want)
+	}
+	if want := (bson.D{{Key: "price", Value: -1}, {Key: idField, Value: -1}}); !reflect.DeepEqual(opts.Sort, want) {
+		t.Errorf("BuildFindOptions() sort = %v, want %v", opts.Sort, want)
+	}
+	if opts.Skip == nil || *opts.Skip != 40 || opts.Limit == nil || *opts.Limit != 20 {
+		t.Errorf("BuildFindOptions() skip, limit = %v, %v, want 40, 20", opts.Skip, opts.Limit)
+	}
+	if opts.Projection != nil {
+		t.Errorf("BuildFindOptions() projection = %v, want none", opts.Projection)
+	}
+}
+
+func TestBuildFindOptionsWithoutPaging(t *testing.T) {
+	_, opts, err := BuildFindOptions(query.ResultSelector{}, nil)
+	if err != nil {
+		t.Fatalf("BuildFindOptions() error = %v", err)
+	}
+	if want := (bson.D{{Key: idField, Value: 1}}); !reflect.DeepEqual(opts.Sort, want) {
+		t.Errorf("BuildFindOptions() sort = %v, want %v", opts.Sort, want)
+	}
+	if opts.Skip != nil || opts.Limit != nil {
+		t.Errorf("BuildFindOptions() skip, limit = %v, %v, want none", opts.Skip, opts.Limit)
+	}
+}
+
+func TestBuildFindOptionsTextScore(t *testing.T) {
+	sortByScore := &sorting.Request{Keys: []sorting.Key{{Column: sorting.TextScoreColumn}}}
+
+	selector := query.ResultSelector{
+		Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+			{Name: "name", Operator: filter.CompareOperatorTextContains, Value: "red shoes"},
+		}},
+		Sorting: sortByScore,
+	}
+	_, opts, err := BuildFindOptions(selector, nil)
+	if err != nil {
+		t.Fatalf("BuildFindOptions() error = %v", err)
+	}
+	if want := (bson.M{textScoreField: bson.M{"$meta": "textScore"}}); !reflect.DeepEqual(opts.Projection, want) {
+		t.Errorf("BuildFindOptions() projection = %v, want %v", opts.Projection, want)
+	}
+
+	_, _, err = BuildFindOptions(query.ResultSelector{Sorting: sortByScore}, nil)
+	var response *errs.Response
+	if !errors.As(err, &response) || response.ErrorCode != errs.InvalidRequestError {
+		t.Errorf("BuildFindOptions() without a text filter error = %v, want an InvalidRequestError", err)
+	}
+}
+
+func TestBuildFindOptionsPagingLimits(t *testing.T) {
+	selector := query.ResultSelector{Paging: &paging.Request{PageSize: 250}}
+
+	_, _, err := BuildFindOptions(selector, nil)
+	var response *errs.Response
+	if !errors.As(err, &response) || response.ErrorCode != errs.InvalidPageRequestError {
+		t.Errorf("BuildFindOptions() error = %v, want an InvalidPageRequestError", err)
+	}
+
+	_, opts, err := BuildFindOptions(selector, nil, query.Options{Limits: &paging.Limits{MaxPageSize: 500}})
+	if err != nil {
+		t.Fatalf("BuildFindOptions() with larger limits error = %v", err)
+	}
+	if opts.Limit == nil || *opts.Limit != 250 {
+		t.Errorf("BuildFindOptions() limit = %v, want 250", opts.Limit)
+	}
+}
@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// HasTextIndex reports whether collection has a text index, which $text searches require.
+func HasTextIndex(ctx context.Context, collection *mongo.Collection) (bool, error) {
+	cur, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to list indexes of collection %s: %w", collection.Name(), err)
+	}
+
+	var indexes []struct {
+		Key bson.D `bson:"key"`
+	}
+	if err := cur.All(ctx, &indexes); err != nil {
+		return false, fmt.Errorf("failed to decode indexes of collection %s: %w", collection.Name(), err)
+	}
+
+	for _, index := range indexes {
+		for _, key := range index.Key {
+			if key.Value == "text" {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// CheckTextIndex returns an errs.InvalidRequestError if requestFilter uses the textContains operator
+// and collection has no text index to search, instead of letting the query fail on the server.
+func CheckTextIndex(ctx context.Context, collection *mongo.Collection, requestFilter *filter.Request) error {
+	if len(requestFilter.FieldsWithOperator(filter.CompareOperatorTextContains)) == 0 {
+		return nil
+	}
+
+	ok, err := HasTextIndex(ctx, collection)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("collection %s has no text index for %s filters", collection.Name(), filter.CompareOperatorTextContains))
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// IPValue returns the binary form an IP address may be stored in for the isIpEqualTo and isIpNotEqualTo filters
+// to match CIDR blocks of both families: the 4 bytes of IPv4 addresses, IPv4-mapped IPv6 addresses included,
+// and the 16 bytes of IPv6 addresses, without their zone.
+// MongoDB stores it as binary data, which it orders by length first and then byte by byte,
+// so that a CIDR block of either family is matched as a range of values that never includes addresses of the other family.
+// Fields stored in this form must be listed in query.Options.BinaryIPFields. filter.ValueIP reads the stored form back.
+func IPValue(addr netip.Addr) []byte {
+	return addr.Unmap().WithZone("").AsSlice()
+}
+
+// IPString returns the string form an IP address must be stored in for the isIpEqualTo and isIpNotEqualTo filters
+// to match it in fields not listed in query.Options.BinaryIPFields: the canonical form of netip.Addr.String,
+// with IPv4-mapped IPv6 addresses written as IPv4 addresses and without the zone.
+func IPString(addr netip.Addr) string {
+	return addr.Unmap().WithZone("").String()
+}
+
+// ipCondition returns the condition matching the IP addresses within the range selected by an IP filter value.
+// Addresses stored with IPValue, when binary is set, have single addresses matched by equality and CIDR blocks by the range
+// from their first to their last address. Addresses stored with IPString have single addresses matched by equality
+// and IPv4 CIDR blocks by a regular expression; IPv6 CIDR blocks can only be matched on binary addresses.
+func ipCondition(value any, binary bool) (bson.M, error) {
+	prefix, err := filter.ValueIPPrefix(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !binary {
+		if prefix.IsSingleIP() {
+			return bson.M{"$eq": IPString(prefix.Addr())}, nil
+		}
+		if !prefix.Addr().Is4() {
+			return nil, errors.New("IPv6 CIDR blocks can only be matched on addresses stored with IPValue")
+		}
+		return bson.M{"$regex": ipv4PrefixPattern(prefix)}, nil
+	}
+
+	if prefix.IsSingleIP() {
+		return bson.M{"$eq": IPValue(prefix.Addr())}, nil
+	}
+	first, last := ipRange(prefix)
+	return bson.M{"$gte": IPValue(first), "$lte": IPValue(last)}, nil
+}
+
+// ipRange returns the first and last addresses of a masked prefix.
+func ipRange(prefix netip.Prefix) (first, last netip.Addr) {
+	first = prefix.Addr()
+
+	bytes := first.AsSlice()
+	for i := prefix.Bits(); i < len(bytes)*8; i++ {
+		bytes[i/8] |= 1 << (7 - i%8)
+	}
+	last, _ = netip.AddrFromSlice(bytes)
+
+	return first, last
+}
+
+// ipv4PrefixPattern returns a regular expression matching the IPv4 addresses of a masked prefix written with IPString.
+// Octets fixed by the prefix are matched literally, partially fixed octets by the list of their possible values.
+func ipv4PrefixPattern(prefix netip.Prefix) string {
+	octets := prefix.Addr().As4()
+	parts := make([]string, len(octets))
+	for i, octet := range octets {
+		bits := min(max(prefix.Bits()-8*i, 0), 8)
+		switch bits {
+		case 8:
+			parts[i] = strconv.Itoa(int(octet))
+		case 0:
+			parts[i] = "[0-9]{1,3}"
+		default:
+			values := make([]string, 0, 1<<(8-bits))
+			for v := int(octet); v <= int(octet|0xff>>bits); v++ {
+				values = append(values, strconv.Itoa(v))
+			}
+			parts[i] = fmt.Sprintf("(?:%s)", strings.Join(values, "|"))
+		}
+	}
+	return "^" + strings.Join(parts, `\.`) + "$"
+}
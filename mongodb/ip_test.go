@@ -0,0 +1,167 @@
+package mongodb
+
+import (
+	"bytes"
+	"cmp"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIPConditionBinary(t *testing.T) {
+	ip := func(s string) []byte {
+		return IPValue(netip.MustParseAddr(s))
+	}
+
+	tests := []struct {
+		name    string
+		value   any
+		want    bson.M
+		wantErr bool
+	}{
+		{name: "IPv4 address", value: "10.1.2.3", want: bson.M{"$eq": []byte{10, 1, 2, 3}}},
+		{name: "IPv4-mapped address", value: "::ffff:10.1.2.3", want: bson.M{"$eq": ip("10.1.2.3")}},
+		{name: "IPv6 address", value: "2001:db8::1", want: bson.M{"$eq": ip("2001:db8::1")}},
+		{name: "non canonical IPv6 address", value: "2001:0DB8:0:0::0001", want: bson.M{"$eq": ip("2001:db8::1")}},
+		{name: "IPv4 block", value: "192.168.1.0/24", want: bson.M{"$gte": ip("192.168.1.0"), "$lte": ip("192.168.1.255")}},
+		{name: "unmasked IPv4 block", value: "10.20.30.40/12", want: bson.M{"$gte": ip("10.16.0.0"), "$lte": ip("10.31.255.255")}},
+		{name: "every IPv4 address", value: "0.0.0.0/0", want: bson.M{"$gte": ip("0.0.0.0"), "$lte": ip("255.255.255.255")}},
+		{name: "IPv4-mapped block", value: "::ffff:10.0.0.0/104", want: bson.M{"$gte": ip("10.0.0.0"), "$lte": ip("10.255.255.255")}},
+		{name: "IPv6 block", value: "2001:db8:abcd::/48", want: bson.M{"$gte": ip("2001:db8:abcd::"), "$lte": ip("2001:db8:abcd:ffff:ffff:ffff:ffff:ffff")}},
+		{name: "odd IPv6 block", value: "fe80::/10", want: bson.M{"$gte": ip("fe80::"), "$lte": ip("febf:ffff:ffff:ffff:ffff:ffff:ffff:ffff")}},
+		{name: "single address block", value: "2001:db8::1/128", want: bson.M{"$eq": ip("2001:db8::1")}},
+		{name: "typed prefix", value: netip.MustParsePrefix("172.16.0.0/12"), want: bson.M{"$gte": ip("172.16.0.0"), "$lte": ip("172.31.255.255")}},
+		{name: "invalid address", value: "10.0.0.256", wantErr: true},
+		{name: "invalid block", value: "10.0.0.0/33", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ipCondition(tt.value, true)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ipCondition() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ipCondition() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ipCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIPConditionRange checks the bounds of CIDR blocks against netip.Prefix.Contains, comparing the stored forms
+// as MongoDB compares binary data of the same subtype: by length first, then byte by byte.
+func TestIPConditionBinaryRange(t *testing.T) {
+	prefixes := []string{"10.0.0.0/8", "192.168.1.128/25", "100.64.0.0/10", "2001:db8::/32", "fd00::/7", "::/0", "0.0.0.0/0", "::/64"}
+	addrs := []string{
+		"0.0.0.0", "255.255.255.255", "::", "::ffff:10.0.0.1",
+		"9.255.255.255", "10.0.0.0", "10.255.255.255", "11.0.0.0", "192.168.1.127", "192.168.1.128", "192.168.1.255",
+		"100.63.255.255", "100.64.0.1", "100.127.255.255", "100.128.0.0",
+		"2001:db7:ffff:ffff:ffff:ffff:ffff:ffff", "2001:db8::", "2001:db8:ffff::1", "2001:db9::", "fc00::", "fdff::1", "fe00::", "::1",
+	}
+
+	for _, p := range prefixes {
+		condition, err := ipCondition(p, true)
+		if err != nil {
+			t.Fatalf("ipCondition(%s) error = %v", p, err)
+		}
+		lo, hi := condition["$gte"].([]byte), condition["$lte"].([]byte)
+
+		for _, a := range addrs {
+			addr := netip.MustParseAddr(a)
+			stored := IPValue(addr)
+			got := compareBinary(stored, lo) >= 0 && compareBinary(stored, hi) <= 0
+			// IPv4-mapped addresses are IPv4 addresses, whatever their notation
+			if want := netip.MustParsePrefix(p).Contains(addr.Unmap()); got != want {
+				t.Errorf("%s in range of %s = %t, want %t", a, p, got, want)
+			}
+		}
+	}
+}
+
+// compareBinary compares binary data the way MongoDB orders it.
+func compareBinary(a, b []byte) int {
+	return cmp.Or(cmp.Compare(len(a), len(b)), bytes.Compare(a, b))
+}
+
+func TestIPConditionString(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    bson.M
+		wantErr bool
+	}{
+		{name: "IPv4 address", value: "10.0.0.1", want: bson.M{"$eq": "10.0.0.1"}},
+		{name: "IPv4-mapped address", value: "::ffff:10.0.0.1", want: bson.M{"$eq": "10.0.0.1"}},
+		{name: "non canonical IPv6 address", value: "2001:0DB8:0:0::0001", want: bson.M{"$eq": "2001:db8::1"}},
+		{name: "IPv4 block", value: "192.168.1.0/24", want: bson.M{"$regex": `^192\.168\.1\.[0-9]{1,3}$`}},
+		{name: "partial octet", value: "10.20.30.40/30", want: bson.M{"$regex": `^10\.20\.30\.(?:40|41|42|43)$`}},
+		{name: "single address block", value: "10.0.0.1/32", want: bson.M{"$eq": "10.0.0.1"}},
+		{name: "IPv6 block", value: "2001:db8::/32", wantErr: true},
+		{name: "invalid address", value: "10.0.0.256", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ipCondition(tt.value, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ipCondition() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ipCondition() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ipCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIPConditionStringRange checks the conditions on string-stored addresses against netip.Prefix.Contains,
+// evaluating them on the stored strings the way MongoDB does.
+func TestIPConditionStringRange(t *testing.T) {
+	prefixes := []string{"10.0.0.0/8", "192.168.1.128/25", "100.64.0.0/10", "172.16.0.0/12", "0.0.0.0/0", "10.1.2.3/32", "10.1.2.3"}
+	addrs := []string{
+		"0.0.0.0", "255.255.255.255", "9.255.255.255", "10.0.0.0", "10.1.2.3", "10.255.255.255", "11.0.0.0",
+		"100.63.255.255", "100.64.0.1", "100.127.255.255", "100.128.0.0", "110.64.0.1",
+		"172.15.255.255", "172.16.0.0", "172.31.255.255", "172.32.0.0",
+		"192.168.1.127", "192.168.1.128", "192.168.1.255", "192.168.10.200", "2001:db8::1",
+	}
+
+	for _, p := range prefixes {
+		condition, err := ipCondition(p, false)
+		if err != nil {
+			t.Fatalf("ipCondition(%s) error = %v", p, err)
+		}
+		prefix, _ := netip.ParsePrefix(p)
+		if !prefix.IsValid() {
+			prefix = netip.PrefixFrom(netip.MustParseAddr(p), 32)
+		}
+
+		for _, a := range addrs {
+			addr := netip.MustParseAddr(a)
+			stored := IPString(addr)
+
+			var got bool
+			if pattern, ok := condition["$regex"].(string); ok {
+				got = regexp.MustCompile(pattern).MatchString(stored)
+			} else {
+				got = condition["$eq"] == stored
+			}
+			if want := prefix.Contains(addr); got != want {
+				t.Errorf("%s matched by %s = %t, want %t", a, p, got, want)
+			}
+		}
+	}
+}
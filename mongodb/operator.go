@@ -0,0 +1,231 @@
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// comparisonOperators maps the ordering operators to their MongoDB query operator.
+var comparisonOperators = map[filter.CompareOperator]string{
+	filter.CompareOperatorIsGreaterThan:          "$gt",
+	filter.CompareOperatorIsGreaterThanOrEqualTo: "$gte",
+	filter.CompareOperatorIsLessThan:             "$lt",
+	filter.CompareOperatorIsLessThanOrEqualTo:    "$lte",
+}
+
+// buildFieldQuery translates a single filter field into a MongoDB condition on fieldName, as configured by opts.
+func buildFieldQuery(fieldName string, field filter.RequestField, opts query.Options) (bson.M, error) {
+	field.Value = mongoValue(field.Value)
+
+	switch field.Operator {
+	case filter.CompareOperatorBeginsWith:
+		prefix, err := stringValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: prefixRegex(prefix, "")}, nil
+
+	case filter.CompareOperatorDoesNotBeginWith:
+		prefix, err := stringValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: bson.M{"$not": prefixRegex(prefix, "")}}, nil
+
+	case filter.CompareOperatorContains:
+		return bson.M{fieldName: bson.M{"$in": listValue(field.Value)}}, nil
+
+	case filter.CompareOperatorDoesNotContain:
+		return bson.M{fieldName: bson.M{"$nin": listValue(field.Value)}}, nil
+
+	case filter.CompareOperatorTextContains:
+		// $text searches the text index of the collection, whatever the field name
+		text, err := stringValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$text": bson.M{"$search": text}}, nil
+
+	case filter.CompareOperatorIsEqualTo:
+		if list, ok := filter.ValueList(field.Value); ok {
+			return bson.M{fieldName: bson.M{"$in": list}}, nil
+		}
+		return bson.M{fieldName: field.Value}, nil
+
+	case filter.CompareOperatorIsNumberEqualTo:
+		return bson.M{fieldName: field.Value}, nil
+
+	case filter.CompareOperatorIsStringEqualTo:
+		value, err := stringValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: value}, nil
+
+	case filter.CompareOperatorIsIpEqualTo:
+		condition, err := ipCondition(field.Value, slices.Contains(opts.BinaryIPFields, field.Name))
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: condition}, nil
+
+	case filter.CompareOperatorIsStringCaseInsensitiveEqualTo:
+		value, err := stringValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"}}, nil
+
+	case filter.CompareOperatorIsNotEqualTo:
+		if list, ok := filter.ValueList(field.Value); ok {
+			return bson.M{fieldName: bson.M{"$nin": list}}, nil
+		}
+		return bson.M{fieldName: bson.M{"$ne": field.Value}}, nil
+
+	case filter.CompareOperatorIsNumberNotEqualTo:
+		return bson.M{fieldName: bson.M{"$ne": field.Value}}, nil
+
+	case filter.CompareOperatorIsStringNotEqualTo:
+		value, err := stringValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: bson.M{"$ne": value}}, nil
+
+	case filter.CompareOperatorIsIpNotEqualTo:
+		condition, err := ipCondition(field.Value, slices.Contains(opts.BinaryIPFields, field.Name))
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: bson.M{"$not": condition}}, nil
+
+	case filter.CompareOperatorIsGreaterThan,
+		filter.CompareOperatorIsGreaterThanOrEqualTo,
+		filter.CompareOperatorIsLessThan,
+		filter.CompareOperatorIsLessThanOrEqualTo:
+		return bson.M{fieldName: bson.M{comparisonOperators[field.Operator]: field.Value}}, nil
+
+	case filter.CompareOperatorIsEqualToRating,
+		filter.CompareOperatorIsGreaterThanRating,
+		filter.CompareOperatorIsGreaterThanOrEqualToRating,
+		filter.CompareOperatorIsLessThanRating,
+		filter.CompareOperatorIsLessThanOrEqualToRating:
+		condition, err := ratingCondition(field, opts.RatingScales.Scale(field.Name))
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: condition}, nil
+
+	case filter.CompareOperatorIsNotEqualToRating:
+		condition, err := ratingCondition(field, opts.RatingScales.Scale(field.Name))
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: bson.M{"$not": condition}}, nil
+
+	case filter.CompareOperatorBeforeDate:
+		date, err := filter.ValueTime(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: bson.M{"$lt": date}}, nil
+
+	case filter.CompareOperatorAfterDate:
+		date, err := filter.ValueTime(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: bson.M{"$gt": date}}, nil
+
+	case filter.CompareOperatorBetweenDates:
+		from, to, err := filter.ValueTimeRange(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{fieldName: bson.M{"$gte": from, "$lte": to}}, nil
+
+	case filter.CompareOperatorExists:
+		// a missing value means the field must be present
+		if field.Value == nil {
+			return bson.M{fieldName: bson.M{"$exists": true}}, nil
+		}
+		exists, ok := filter.ValueBool(field.Value)
+		if !ok {
+			return nil, errors.New("operator exists expects a boolean")
+		}
+		return bson.M{fieldName: bson.M{"$exists": exists}}, nil
+
+	default:
+		return nil, fmt.Errorf("operator %q is not supported", field.Operator)
+	}
+}
+
+// ratingCondition returns the bounds of the stored ratings selected by a rating field, as defined by scale.
+// The bounds of isNotEqualToRating are those of isEqualToRating, to be negated.
+func ratingCondition(field filter.RequestField, scale filter.RatingScale) (bson.M, error) {
+	stars, ok := filter.ValueNumber(field.Value)
+	if !ok {
+		return nil, fmt.Errorf("expected a number value, got %T", field.Value)
+	}
+
+	from, to, err := scale.Range(field.Operator, stars)
+	if err != nil {
+		return nil, err
+	}
+
+	condition := bson.M{}
+	if !math.IsInf(from, -1) {
+		condition["$gte"] = from
+	}
+	if !math.IsInf(to, 1) {
+		condition["$lt"] = to
+	}
+	return condition, nil
+}
+
+// mongoValue converts typed filter values to the form they are stored in.
+// UUIDs are stored in their string form, as generated by the idgenerator package.
+func mongoValue(value any) any {
+	if id, ok := value.(uuid.UUID); ok {
+		return id.String()
+	}
+	if list, ok := value.([]any); ok {
+		converted := make([]any, len(list))
+		for i, element := range list {
+			converted[i] = mongoValue(element)
+		}
+		return converted
+	}
+	return value
+}
+
+// prefixRegex returns an anchored regular expression matching values starting with prefix.
+func prefixRegex(prefix, options string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix), Options: options}
+}
+
+// stringValue returns value as a string, or an error if it is not a single string.
+func stringValue(value any) (string, error) {
+	s, ok := filter.ValueString(value)
+	if !ok {
+		return "", fmt.Errorf("expected a string value, got %T", value)
+	}
+	return s, nil
+}
+
+// listValue returns value as a list, wrapping single values in a one-element list.
+func listValue(value any) []any {
+	if list, ok := filter.ValueList(value); ok {
+		return list
+	}
+	return []any{value}
+}
@@ -1,64 +1,199 @@
 package mongodb
 
 import (
-	"reflect"
+	"fmt"
+	"strings"
 
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query"
 	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// BuildMongoFilterQuery constructs a MongoDB filter query based on the provided request filter
-// and a field mapping. It supports both "and" and "or" operators for combining field conditions.
-// TODO: handle filter field mapping better
-func BuildMongoFilterQuery(requestFilter *filter.Request, fieldMapping map[string]string) bson.M {
-	query := bson.M{}
+// matchNothing returns a filter that no document satisfies.
+// A new map is returned on every call, as callers may add conditions to the filters they receive.
+func matchNothing() bson.M {
+	return bson.M{"$expr": false}
+}
 
+// BuildMongoFilter constructs a MongoDB filter query based on the provided request filter
+// and a field mapping. It supports both "and" and "or" operators for combining field conditions,
+// nested groups of conditions, and translates every filter.CompareOperator into its MongoDB equivalent.
+// textContains becomes a $text search on the collection's text index; a request may hold only one of them.
+// isIpEqualTo and isIpNotEqualTo match addresses stored as strings in the form returned by IPString,
+// or in the binary form returned by IPValue for the fields listed in the BinaryIPFields of queryOptions.
+// The rating operators use the rating scales of queryOptions, if given, falling back to filter.DefaultRatingScale.
+// The request is validated first; an errs.InvalidRequestError naming the offending field is returned
+// if the logic operator is unknown, a compare operator is invalid or a value does not fit its operator.
+func BuildMongoFilter(requestFilter *filter.Request, fieldMapping map[string]string, queryOptions ...query.Options) (bson.M, error) {
 	if requestFilter == nil {
-		return query
+		return bson.M{}, nil
+	}
+
+	opts := query.FirstOptions(queryOptions)
+	if err := requestFilter.Validate(opts.RatingScales); err != nil {
+		return nil, err
+	}
+
+	if len(requestFilter.FieldsWithOperator(filter.CompareOperatorTextContains)) > 1 {
+		return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("only one %s filter is allowed", filter.CompareOperatorTextContains))
+	}
+
+	return buildGroupQuery(requestFilter, fieldMapping, opts)
+}
+
+// buildGroupQuery translates a filter request and its nested groups into a MongoDB query.
+// Groups without any condition match every document.
+func buildGroupQuery(requestFilter *filter.Request, fieldMapping map[string]string, opts query.Options) (bson.M, error) {
+	conditions := make([]bson.M, 0, len(requestFilter.Fields)+len(requestFilter.Groups))
+	for _, field := range requestFilter.Fields {
+		fieldQuery, err := buildFieldQuery(mappedFieldName(field.Name, fieldMapping), field, opts)
+		if err != nil {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: %w", field.Name, err))
+		}
+		conditions = append(conditions, fieldQuery)
 	}
 
-	// Helper function to build individual field queries
-	buildFieldQuery := func(field filter.RequestField) bson.M {
-		// Use the mapped field name if it exists, otherwise use the original field name
-		fieldName := fieldMapping[field.Name]
-		if fieldName == "" {
-			fieldName = field.Name
+	for i := range requestFilter.Groups {
+		groupQuery, err := buildGroupQuery(&requestFilter.Groups[i], fieldMapping, opts)
+		if err != nil {
+			return nil, err
 		}
-		if field.Operator == filter.CompareOperatorContains || reflect.TypeOf(field.Value).Kind() == reflect.Slice {
-			return bson.M{fieldName: bson.M{"$in": field.Value}}
+		if len(groupQuery) > 0 {
+			conditions = append(conditions, groupQuery)
 		}
-		return bson.M{fieldName: field.Value}
+	}
+
+	if len(conditions) == 0 {
+		return bson.M{}, nil
 	}
 
 	switch requestFilter.Operator {
 	case filter.LogicOperatorAnd:
-		for _, field := range requestFilter.Fields {
-			fieldQuery := buildFieldQuery(field)
-			for key, value := range fieldQuery {
+		return andQuery(conditions), nil
+	case filter.LogicOperatorOr:
+		return bson.M{"$or": conditions}, nil
+	default:
+		return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("logic operator %q is not supported", requestFilter.Operator))
+	}
+}
+
+// andQuery combines conditions so that every one of them must hold.
+// Conditions on distinct fields are merged into a single document, and operator documents on the same field
+// are merged when their operators do not overlap, e.g. {price: {$gt: 100}} and {price: {$lt: 500}}
+// become {price: {$gt: 100, $lt: 500}}. Conditions that cannot be merged are kept side by side in an $and array.
+func andQuery(conditions []bson.M) bson.M {
+	query := bson.M{}
+	var unmerged []bson.M
+
+	for _, condition := range conditions {
+		if !canMerge(query, condition) {
+			unmerged = append(unmerged, condition)
+			continue
+		}
+		for key, value := range condition {
+			existing, ok := query[key]
+			if !ok {
 				query[key] = value
+				continue
 			}
+			merged := bson.M{}
+			for operator, operand := range existing.(bson.M) {
+				merged[operator] = operand
+			}
+			for operator, operand := range value.(bson.M) {
+				merged[operator] = operand
+			}
+			query[key] = merged
 		}
-	case filter.LogicOperatorOr:
-		orConditions := make([]bson.M, len(requestFilter.Fields))
-		for i, field := range requestFilter.Fields {
-			orConditions[i] = buildFieldQuery(field)
+	}
+
+	if len(unmerged) > 0 {
+		query["$and"] = unmerged
+	}
+
+	return query
+}
+
+// canMerge reports whether every key of condition can be added to query without overwriting an existing condition.
+func canMerge(query, condition bson.M) bool {
+	for key, value := range condition {
+		// $and is reserved for the conditions that could not be merged
+		if key == "$and" {
+			return false
+		}
+		existing, ok := query[key]
+		if !ok {
+			continue
+		}
+		existingOperators, ok := operatorDocument(existing)
+		if !ok {
+			return false
+		}
+		operators, ok := operatorDocument(value)
+		if !ok {
+			return false
+		}
+		for operator := range operators {
+			if _, ok := existingOperators[operator]; ok {
+				return false
+			}
 		}
-		query["$or"] = orConditions
 	}
+	return true
+}
 
+// operatorDocument returns value as a document of query operators such as {$gt: 1, $lt: 5}.
+// Plain values, regular expressions and documents holding field names are not operator documents.
+func operatorDocument(value any) (bson.M, bool) {
+	document, ok := value.(bson.M)
+	if !ok || len(document) == 0 {
+		return nil, false
+	}
+	for key := range document {
+		if !strings.HasPrefix(key, "$") {
+			return nil, false
+		}
+	}
+	return document, true
+}
+
+// BuildMongoFilterQuery constructs a MongoDB filter query based on the provided request filter
+// and a field mapping. It supports both "and" and "or" operators for combining field conditions.
+// If the request filter cannot be translated, the error is logged and a query matching no documents is returned.
+//
+// Deprecated: Use BuildMongoFilter, which reports invalid filters to the caller.
+func BuildMongoFilterQuery(requestFilter *filter.Request, fieldMapping map[string]string) bson.M {
+	query, err := BuildMongoFilter(requestFilter, fieldMapping)
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid filter request, matching no documents")
+		return matchNothing()
+	}
 	return query
 }
 
+// mappedFieldName returns the mapped field name if it exists, otherwise the original field name.
+func mappedFieldName(name string, fieldMapping map[string]string) string {
+	if mapped := fieldMapping[name]; mapped != "" {
+		return mapped
+	}
+	return name
+}
+
 // GetPaginatedOpts returns MongoDB find options for pagination.
 // It calculates the number of documents to skip and the limit based on the given page size and page index.
-// If pageIndex or pageSize are less than 1, it sets sensible defaults.
+// Unlike paging.Request, pageIndex starts from 1 here. If pageIndex or pageSize are less than 1, it sets sensible defaults.
+//
+// Deprecated: Use BuildFindOptions, which validates the paging request and uses the zero-based page index of paging.Request.
 func GetPaginatedOpts(pageSize, pageIndex int64) *options.FindOptions {
 	if pageIndex < 1 {
 		pageIndex = 1
 	}
 	if pageSize < 1 {
-		pageSize = 10 // or a sensible default
+		pageSize = paging.DefaultPageSize
 	}
 
 	skip := pageSize * (pageIndex - 1)
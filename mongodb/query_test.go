@@ -0,0 +1,226 @@
+package mongodb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBuildMongoFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      *filter.Request
+		fieldMapping map[string]string
+		options      query.Options
+		want         bson.M
+		wantErr      bool
+	}{
+		{
+			name:    "nil request",
+			request: nil,
+			want:    bson.M{},
+		},
+		{
+			name: "price range merged into one condition",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 100},
+				{Name: "price", Operator: filter.CompareOperatorIsLessThan, Value: 500},
+			}},
+			want: bson.M{"price": bson.M{"$gt": 100, "$lt": 500}},
+		},
+		{
+			name: "overlapping operators kept in $and",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 100},
+				{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 200},
+			}},
+			want: bson.M{
+				"price": bson.M{"$gt": 100},
+				"$and":  []bson.M{{"price": bson.M{"$gt": 200}}},
+			},
+		},
+		{
+			name: "equality and operator on the same field",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "age", Operator: filter.CompareOperatorIsEqualTo, Value: 30},
+				{Name: "age", Operator: filter.CompareOperatorIsGreaterThan, Value: 18},
+			}},
+			want: bson.M{
+				"age":  30,
+				"$and": []bson.M{{"age": bson.M{"$gt": 18}}},
+			},
+		},
+		{
+			name: "or of fields",
+			request: &filter.Request{Operator: filter.LogicOperatorOr, Fields: []filter.RequestField{
+				{Name: "city", Operator: filter.CompareOperatorIsEqualTo, Value: "Lagos"},
+				{Name: "city", Operator: filter.CompareOperatorIsEqualTo, Value: "Abuja"},
+			}},
+			want: bson.M{"$or": []bson.M{{"city": "Lagos"}, {"city": "Abuja"}}},
+		},
+		{
+			name: "nested or groups",
+			request: &filter.Request{
+				Operator: filter.LogicOperatorAnd,
+				Fields: []filter.RequestField{
+					{Name: "status", Operator: filter.CompareOperatorIsEqualTo, Value: "active"},
+				},
+				Groups: []filter.Request{
+					{Operator: filter.LogicOperatorOr, Fields: []filter.RequestField{
+						{Name: "city", Operator: filter.CompareOperatorIsEqualTo, Value: "Lagos"},
+						{Name: "city", Operator: filter.CompareOperatorIsEqualTo, Value: "Abuja"},
+					}},
+					{Operator: filter.LogicOperatorOr, Fields: []filter.RequestField{
+						{Name: "verified", Operator: filter.CompareOperatorIsEqualTo, Value: true},
+						{Name: "rating", Operator: filter.CompareOperatorIsGreaterThanOrEqualTo, Value: 4},
+					}},
+				},
+			},
+			want: bson.M{
+				"status": "active",
+				"$or":    []bson.M{{"city": "Lagos"}, {"city": "Abuja"}},
+				"$and": []bson.M{
+					{"$or": []bson.M{{"verified": true}, {"rating": bson.M{"$gte": 4}}}},
+				},
+			},
+		},
+		{
+			name: "empty nested group dropped",
+			request: &filter.Request{
+				Operator: filter.LogicOperatorAnd,
+				Fields: []filter.RequestField{
+					{Name: "status", Operator: filter.CompareOperatorIsEqualTo, Value: "active"},
+				},
+				Groups: []filter.Request{{Operator: filter.LogicOperatorOr}},
+			},
+			want: bson.M{"status": "active"},
+		},
+		{
+			name: "text search",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "description", Operator: filter.CompareOperatorTextContains, Value: "red shoes"},
+				{Name: "price", Operator: filter.CompareOperatorIsLessThanOrEqualTo, Value: 50},
+			}},
+			want: bson.M{
+				"$text": bson.M{"$search": "red shoes"},
+				"price": bson.M{"$lte": 50},
+			},
+		},
+		{
+			name: "more than one text search",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "description", Operator: filter.CompareOperatorTextContains, Value: "red"},
+				{Name: "title", Operator: filter.CompareOperatorTextContains, Value: "shoes"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "list values and field mapping",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "status", Operator: filter.CompareOperatorIsEqualTo, Value: []any{"active", "pending"}},
+				{Name: "category", Operator: filter.CompareOperatorDoesNotContain, Value: "archived"},
+			}},
+			fieldMapping: map[string]string{"status": "state.status"},
+			want: bson.M{
+				"state.status": bson.M{"$in": []any{"active", "pending"}},
+				"category":     bson.M{"$nin": []any{"archived"}},
+			},
+		},
+		{
+			name: "IP addresses stored as strings",
+			request: &filter.Request{Operator: filter.LogicOperatorOr, Fields: []filter.RequestField{
+				{Name: "ip", Operator: filter.CompareOperatorIsIpEqualTo, Value: "10.0.0.1"},
+				{Name: "ip", Operator: filter.CompareOperatorIsIpNotEqualTo, Value: "10.0.0.0/8"},
+			}},
+			want: bson.M{"$or": []bson.M{
+				{"ip": bson.M{"$eq": "10.0.0.1"}},
+				{"ip": bson.M{"$not": bson.M{"$regex": `^10\.[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}$`}}},
+			}},
+		},
+		{
+			name: "IPv6 block on addresses stored as strings",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "ip", Operator: filter.CompareOperatorIsIpEqualTo, Value: "2001:db8::/32"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "IP blocks on binary addresses",
+			request: &filter.Request{Operator: filter.LogicOperatorOr, Fields: []filter.RequestField{
+				{Name: "ip", Operator: filter.CompareOperatorIsIpEqualTo, Value: "2001:db8::/32"},
+				{Name: "ip", Operator: filter.CompareOperatorIsIpNotEqualTo, Value: "10.0.0.0/8"},
+			}},
+			options: query.Options{BinaryIPFields: []string{"ip"}},
+			want: bson.M{"$or": []bson.M{
+				{"ip": bson.M{
+					"$gte": []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+					"$lte": []byte{0x20, 0x01, 0x0d, 0xb8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+				}},
+				{"ip": bson.M{"$not": bson.M{"$gte": []byte{10, 0, 0, 0}, "$lte": []byte{10, 255, 255, 255}}}},
+			}},
+		},
+		{
+			name: "rating on the default scale",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "rating", Operator: filter.CompareOperatorIsEqualToRating, Value: 4},
+			}},
+			want: bson.M{"rating": bson.M{"$gte": 3.5, "$lt": 4.5}},
+		},
+		{
+			name: "rating on the scale of its field",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "score", Operator: filter.CompareOperatorIsGreaterThanOrEqualToRating, Value: 8},
+			}},
+			options: query.Options{RatingScales: filter.RatingScales{
+				"score": {Min: 0, Max: 10, Step: 1, Rounding: filter.RatingRoundDown},
+			}},
+			want: bson.M{"score": bson.M{"$gte": 8.0}},
+		},
+		{
+			name: "rating outside of the default scale",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "score", Operator: filter.CompareOperatorIsGreaterThanOrEqualToRating, Value: 8},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid value",
+			request: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "password", Operator: filter.CompareOperatorIsEqualTo, Value: map[string]any{"$ne": nil}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildMongoFilter(tt.request, tt.fieldMapping, tt.options)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("BuildMongoFilter() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildMongoFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildMongoFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildMongoFilterQueryReturnsFreshFilters(t *testing.T) {
+	invalid := &filter.Request{Operator: "xor"}
+
+	first := BuildMongoFilterQuery(invalid, nil)
+	first["tenant"] = "a"
+
+	if second := BuildMongoFilterQuery(invalid, nil); !reflect.DeepEqual(second, bson.M{"$expr": false}) {
+		t.Errorf("BuildMongoFilterQuery() = %v after a caller changed a previous filter, want %v", second, bson.M{"$expr": false})
+	}
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+)
+
+// comparisonOperators maps the ordering operators to their SQL operator.
+var comparisonOperators = map[filter.CompareOperator]string{
+	filter.CompareOperatorIsGreaterThan:          ">",
+	filter.CompareOperatorIsGreaterThanOrEqualTo: ">=",
+	filter.CompareOperatorIsLessThan:             "<",
+	filter.CompareOperatorIsLessThanOrEqualTo:    "<=",
+}
+
+// likeEscaper escapes the LIKE wildcards so user input is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// buildFieldCondition translates a single filter field into an SQL condition on column.
+func (b *queryBuilder) buildFieldCondition(column string, field filter.RequestField) (string, error) {
+	switch field.Operator {
+	case filter.CompareOperatorBeginsWith:
+		return fmt.Sprintf("%s LIKE %s", column, b.bind(likeEscaper.Replace(stringValue(field.Value))+"%")), nil
+
+	case filter.CompareOperatorDoesNotBeginWith:
+		return fmt.Sprintf("(%s IS NULL OR %s NOT LIKE %s)", column, column, b.bind(likeEscaper.Replace(stringValue(field.Value))+"%")), nil
+
+	case filter.CompareOperatorContains:
+		return fmt.Sprintf("%s IN (%s)", column, b.bindList(listValue(field.Value))), nil
+
+	case filter.CompareOperatorDoesNotContain:
+		return fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", column, column, b.bindList(listValue(field.Value))), nil
+
+	case filter.CompareOperatorTextContains:
+		return fmt.Sprintf("%s @@ %s", b.tsvector(column), b.tsquery(stringValue(field.Value))), nil
+
+	case filter.CompareOperatorIsEqualTo:
+		if list, ok := filter.ValueList(field.Value); ok {
+			return fmt.Sprintf("%s IN (%s)", column, b.bindList(list)), nil
+		}
+		return fmt.Sprintf("%s = %s", column, b.bind(field.Value)), nil
+
+	case filter.CompareOperatorIsNumberEqualTo,
+		filter.CompareOperatorIsStringEqualTo:
+		return fmt.Sprintf("%s = %s", column, b.bind(field.Value)), nil
+
+	case filter.CompareOperatorIsStringCaseInsensitiveEqualTo:
+		return fmt.Sprintf("%s ILIKE %s", column, b.bind(likeEscaper.Replace(stringValue(field.Value)))), nil
+
+	case filter.CompareOperatorIsIpEqualTo:
+		prefix, err := filter.ValueIPPrefix(field.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s::inet <<= %s::inet", column, b.bind(prefix.String())), nil
+
+	case filter.CompareOperatorIsNotEqualTo:
+		if list, ok := filter.ValueList(field.Value); ok {
+			return fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", column, column, b.bindList(list)), nil
+		}
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", column, b.bind(field.Value)), nil
+
+	case filter.CompareOperatorIsNumberNotEqualTo,
+		filter.CompareOperatorIsStringNotEqualTo:
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", column, b.bind(field.Value)), nil
+
+	case filter.CompareOperatorIsIpNotEqualTo:
+		prefix, err := filter.ValueIPPrefix(field.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s IS NULL OR NOT %s::inet <<= %s::inet)", column, column, b.bind(prefix.String())), nil
+
+	case filter.CompareOperatorIsGreaterThan,
+		filter.CompareOperatorIsGreaterThanOrEqualTo,
+		filter.CompareOperatorIsLessThan,
+		filter.CompareOperatorIsLessThanOrEqualTo:
+		return fmt.Sprintf("%s %s %s", column, comparisonOperators[field.Operator], b.bind(field.Value)), nil
+
+	case filter.CompareOperatorIsEqualToRating,
+		filter.CompareOperatorIsGreaterThanRating,
+		filter.CompareOperatorIsGreaterThanOrEqualToRating,
+		filter.CompareOperatorIsLessThanRating,
+		filter.CompareOperatorIsLessThanOrEqualToRating:
+		return b.buildRatingCondition(column, field)
+
+	case filter.CompareOperatorIsNotEqualToRating:
+		condition, err := b.buildRatingCondition(column, field)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s IS NULL OR NOT %s)", column, condition), nil
+
+	case filter.CompareOperatorBeforeDate:
+		date, err := filter.ValueTime(field.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s < %s", column, b.bind(date)), nil
+
+	case filter.CompareOperatorAfterDate:
+		date, err := filter.ValueTime(field.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s > %s", column, b.bind(date)), nil
+
+	case filter.CompareOperatorBetweenDates:
+		from, to, err := filter.ValueTimeRange(field.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s BETWEEN %s AND %s", column, b.bind(from), b.bind(to)), nil
+
+	case filter.CompareOperatorExists:
+		// a missing value means the column must be set
+		if exists, ok := filter.ValueBool(field.Value); ok && !exists {
+			return fmt.Sprintf("%s IS NULL", column), nil
+		}
+		return fmt.Sprintf("%s IS NOT NULL", column), nil
+
+	default:
+		return "", fmt.Errorf("operator %q is not supported", field.Operator)
+	}
+}
+
+// buildRatingCondition returns the condition on the stored ratings selected by a rating field, as defined by
+// the rating scale of the field in the builder options, or filter.DefaultRatingScale.
+// The condition of isNotEqualToRating is that of isEqualToRating, to be negated.
+func (b *queryBuilder) buildRatingCondition(column string, field filter.RequestField) (string, error) {
+	stars, _ := filter.ValueNumber(field.Value)
+	from, to, err := b.options.RatingScales.Scale(field.Name).Range(field.Operator, stars)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case math.IsInf(from, -1):
+		return fmt.Sprintf("%s < %s", column, b.bind(to)), nil
+	case math.IsInf(to, 1):
+		return fmt.Sprintf("%s >= %s", column, b.bind(from)), nil
+	default:
+		return fmt.Sprintf("(%s >= %s AND %s < %s)", column, b.bind(from), column, b.bind(to)), nil
+	}
+}
+
+// stringValue returns value as a string. The value shape is checked by filter.Request.Validate beforehand.
+func stringValue(value any) string {
+	s, _ := filter.ValueString(value)
+	return s
+}
+
+// listValue returns value as a list, wrapping single values in a one-element list.
+func listValue(value any) []any {
+	if list, ok := filter.ValueList(value); ok {
+		return list
+	}
+	return []any{value}
+}
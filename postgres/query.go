@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+// DefaultTextSearchConfig is the text search configuration of textContains filters when Options do not set one.
+const DefaultTextSearchConfig = "english"
+
+// textSearchConfigPattern matches the names of text search configurations, optionally qualified by their schema.
+var textSearchConfigPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Options configures how BuildQueryWithOptions translates a result selector.
+//   - Options: The options shared by every query builder, such as the paging limits.
+//   - TextSearchConfig: The text search configuration textContains filters parse text with, such as "english" or "simple".
+//     DefaultTextSearchConfig when empty. It is written into the SQL as a literal, so that the expression
+//     to_tsvector('<config>', column) can be served by an index on that same expression.
+type Options struct {
+	query.Options
+	TextSearchConfig string
+}
+
+// textSearchConfig returns the text search configuration of the options.
+func (o Options) textSearchConfig() string {
+	if o.TextSearchConfig == "" {
+		return DefaultTextSearchConfig
+	}
+	return o.TextSearchConfig
+}
+
+// queryBuilder accumulates the positional arguments referenced by the generated SQL.
+type queryBuilder struct {
+	columns map[string]string
+	options Options
+	args    []any
+}
+
+// BuildQuery translates a query.ResultSelector into a parameterized SQL clause of the form
+// "WHERE ... ORDER BY ... LIMIT $n OFFSET $m" along with its positional arguments.
+//
+// columns maps every filterable and sortable field name to the SQL column expression it refers to.
+// Field names that are not in columns are rejected, so user input never ends up in the SQL text.
+// args are the arguments already used by the caller's own statement; the generated placeholders
+// are numbered after them and the returned arguments start with them.
+// textContains filters are full-text searches using DefaultTextSearchConfig, and sorting by sorting.TextScoreColumn
+// orders rows by their rank.
+// Paging uses a zero-based page index.
+// An errs.InvalidRequestError is returned if the selector cannot be translated, and an errs.InvalidPageRequestError
+// if the paging request exceeds paging.DefaultLimits.
+func BuildQuery(resultSelector query.ResultSelector, columns map[string]string, args ...any) (string, []any, error) {
+	return BuildQueryWithOptions(resultSelector, columns, Options{}, args...)
+}
+
+// BuildQueryWithOptions is like BuildQuery, with the paging request validated against the limits of options
+// rather than paging.DefaultLimits, full-text searches using the text search configuration of options
+// and the rating operators using its rating scales.
+func BuildQueryWithOptions(resultSelector query.ResultSelector, columns map[string]string, options Options, args ...any) (string, []any, error) {
+	if !textSearchConfigPattern.MatchString(options.textSearchConfig()) {
+		return "", nil, fmt.Errorf("invalid text search configuration %q", options.TextSearchConfig)
+	}
+
+	b := &queryBuilder{
+		columns: columns,
+		options: options,
+		args:    append([]any(nil), args...),
+	}
+
+	var clauses []string
+
+	where, err := b.buildWhere(resultSelector.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+	if where != "" {
+		clauses = append(clauses, "WHERE "+where)
+	}
+
+	orderBy, err := b.buildOrderBy(resultSelector.Sorting, resultSelector.Filter)
+	if err != nil {
+		return "", nil, err
+	}
+	if orderBy != "" {
+		clauses = append(clauses, "ORDER BY "+orderBy)
+	}
+
+	if err := resultSelector.Paging.Validate(b.options.PagingLimits()); err != nil {
+		return "", nil, err
+	}
+	if resultSelector.Paging != nil {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %s OFFSET %s",
+			b.bind(resultSelector.Paging.Limit()), b.bind(resultSelector.Paging.Offset())))
+	}
+
+	return strings.Join(clauses, " "), b.args, nil
+}
+
+// buildWhere validates the filter request and translates it into an SQL condition without the WHERE keyword.
+func (b *queryBuilder) buildWhere(requestFilter *filter.Request) (string, error) {
+	if requestFilter == nil {
+		return "", nil
+	}
+
+	if err := requestFilter.Validate(b.options.RatingScales); err != nil {
+		return "", err
+	}
+
+	return b.buildGroupCondition(requestFilter)
+}
+
+// buildGroupCondition translates a filter request and its nested groups into an SQL condition.
+// Groups without any condition are left out.
+func (b *queryBuilder) buildGroupCondition(requestFilter *filter.Request) (string, error) {
+	conditions := make([]string, 0, len(requestFilter.Fields)+len(requestFilter.Groups))
+	for _, field := range requestFilter.Fields {
+		column, err := b.column(field.Name)
+		if err != nil {
+			return "", err
+		}
+		condition, err := b.buildFieldCondition(column, field)
+		if err != nil {
+			return "", errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: %w", field.Name, err))
+		}
+		conditions = append(conditions, condition)
+	}
+
+	for i := range requestFilter.Groups {
+		condition, err := b.buildGroupCondition(&requestFilter.Groups[i])
+		if err != nil {
+			return "", err
+		}
+		if condition != "" {
+			conditions = append(conditions, "("+condition+")")
+		}
+	}
+
+	switch requestFilter.Operator {
+	case filter.LogicOperatorAnd:
+		return strings.Join(conditions, " AND "), nil
+	case filter.LogicOperatorOr:
+		return strings.Join(conditions, " OR "), nil
+	default:
+		return "", errs.Body(errs.InvalidRequestError, fmt.Errorf("logic operator %q is not supported", requestFilter.Operator))
+	}
+}
+
+// buildOrderBy translates a sorting request into an SQL ordering without the ORDER BY keywords.
+// Sorting by sorting.TextScoreColumn ranks rows by their relevance to the textContains filter of requestFilter.
+func (b *queryBuilder) buildOrderBy(sortingRequest *sorting.Request, requestFilter *filter.Request) (string, error) {
+	keys := sortingRequest.SortKeys()
+	orderings := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if key.Column == sorting.TextScoreColumn {
+			ranking, err := b.buildTextRank(requestFilter)
+			if err != nil {
+				return "", err
+			}
+			orderings = append(orderings, ranking+" "+sorting.DirectionDescending.String())
+			continue
+		}
+
+		column, err := b.column(key.Column)
+		if err != nil {
+			return "", err
+		}
+
+		direction := sorting.SortDirectionFromString(string(key.Direction))
+		if direction == sorting.NoDirection {
+			if key.Direction != sorting.NoDirection {
+				return "", errs.Body(errs.InvalidRequestError, fmt.Errorf("sort direction %q is not supported", key.Direction))
+			}
+			direction = sorting.DirectionAscending
+		}
+
+		orderings = append(orderings, column+" "+direction.String())
+	}
+
+	return strings.Join(orderings, ", "), nil
+}
+
+// buildTextRank returns the expression ranking rows by relevance to the textContains filters of requestFilter.
+func (b *queryBuilder) buildTextRank(requestFilter *filter.Request) (string, error) {
+	fields := requestFilter.FieldsWithOperator(filter.CompareOperatorTextContains)
+	if len(fields) == 0 {
+		return "", errs.Body(errs.InvalidRequestError, fmt.Errorf("sorting by %s requires a %s filter", sorting.TextScoreColumn, filter.CompareOperatorTextContains))
+	}
+
+	ranks := make([]string, len(fields))
+	for i, field := range fields {
+		column, err := b.column(field.Name)
+		if err != nil {
+			return "", err
+		}
+		ranks[i] = fmt.Sprintf("ts_rank(%s, %s)", b.tsvector(column), b.tsquery(stringValue(field.Value)))
+	}
+
+	return strings.Join(ranks, " + "), nil
+}
+
+// tsvector returns the expression parsing column into a text search document.
+func (b *queryBuilder) tsvector(column string) string {
+	return fmt.Sprintf("to_tsvector('%s', %s)", b.options.textSearchConfig(), column)
+}
+
+// tsquery binds text and returns the expression parsing it into a text search query.
+func (b *queryBuilder) tsquery(text string) string {
+	return fmt.Sprintf("plainto_tsquery('%s', %s)", b.options.textSearchConfig(), b.bind(text))
+}
+
+// column returns the SQL column expression for a field name, or an error if the field is not allowed.
+func (b *queryBuilder) column(name string) (string, error) {
+	column, ok := b.columns[name]
+	if !ok || column == "" {
+		return "", errs.Body(errs.InvalidRequestError, fmt.Errorf("field %q is not allowed", name))
+	}
+	return column, nil
+}
+
+// bind adds value to the arguments and returns its placeholder.
+func (b *queryBuilder) bind(value any) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// bindList adds every value to the arguments and returns their comma separated placeholders.
+func (b *queryBuilder) bindList(values []any) string {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = b.bind(value)
+	}
+	return strings.Join(placeholders, ", ")
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/query"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+var testColumns = map[string]string{
+	"name":        "u.name",
+	"age":         "u.age",
+	"description": "u.description",
+}
+
+func TestBuildQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector query.ResultSelector
+		args     []any
+		wantSQL  string
+		wantArgs []any
+		wantErr  bool
+	}{
+		{
+			name:     "empty selector",
+			selector: query.ResultSelector{},
+			wantSQL:  "",
+			wantArgs: nil,
+		},
+		{
+			name: "begins with",
+			selector: query.ResultSelector{Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "name", Operator: filter.CompareOperatorBeginsWith, Value: "50%_"},
+			}}},
+			wantSQL:  `WHERE u.name LIKE $1`,
+			wantArgs: []any{`50\%\_%`},
+		},
+		{
+			name: "does not begin with keeps null rows",
+			selector: query.ResultSelector{Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "name", Operator: filter.CompareOperatorDoesNotBeginWith, Value: "jo"},
+			}}},
+			wantSQL:  `WHERE (u.name IS NULL OR u.name NOT LIKE $1)`,
+			wantArgs: []any{"jo%"},
+		},
+		{
+			name: "nested or group after caller arguments",
+			selector: query.ResultSelector{Filter: &filter.Request{
+				Operator: filter.LogicOperatorAnd,
+				Fields: []filter.RequestField{
+					{Name: "age", Operator: filter.CompareOperatorIsGreaterThanOrEqualTo, Value: 18},
+				},
+				Groups: []filter.Request{{Operator: filter.LogicOperatorOr, Fields: []filter.RequestField{
+					{Name: "name", Operator: filter.CompareOperatorIsEqualTo, Value: "john"},
+					{Name: "name", Operator: filter.CompareOperatorIsNotEqualTo, Value: []any{"jane", "joe"}},
+				}}},
+			}},
+			args:     []any{"tenant"},
+			wantSQL:  `WHERE u.age >= $2 AND (u.name = $3 OR (u.name IS NULL OR u.name NOT IN ($4, $5)))`,
+			wantArgs: []any{"tenant", 18, "john", "jane", "joe"},
+		},
+		{
+			name: "sorting and paging",
+			selector: query.ResultSelector{
+				Sorting: &sorting.Request{Keys: []sorting.Key{{Column: "age", Direction: sorting.DirectionDescending}}},
+				Paging:  &paging.Request{PageIndex: 2, PageSize: 10},
+			},
+			wantSQL:  `ORDER BY u.age DESC LIMIT $1 OFFSET $2`,
+			wantArgs: []any{10, 20},
+		},
+		{
+			name: "unknown column",
+			selector: query.ResultSelector{Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "password", Operator: filter.CompareOperatorIsEqualTo, Value: "x"},
+			}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := BuildQuery(tt.selector, testColumns, tt.args...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("BuildQuery() = %q, want an error", sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildQuery() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("BuildQuery() sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("BuildQuery() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildQueryWithOptionsLimits(t *testing.T) {
+	selector := query.ResultSelector{Paging: &paging.Request{PageIndex: 1, PageSize: 500}}
+
+	if _, _, err := BuildQuery(selector, testColumns); err == nil {
+		t.Error("BuildQuery() error = nil, want the page size to exceed the default limits")
+	}
+
+	options := Options{Options: query.Options{Limits: &paging.Limits{MaxPageSize: 1000}}}
+	sql, args, err := BuildQueryWithOptions(selector, testColumns, options)
+	if err != nil {
+		t.Fatalf("BuildQueryWithOptions() error = %v", err)
+	}
+	if want := "LIMIT $1 OFFSET $2"; sql != want {
+		t.Errorf("BuildQueryWithOptions() sql = %q, want %q", sql, want)
+	}
+	if want := []any{500, 500}; !reflect.DeepEqual(args, want) {
+		t.Errorf("BuildQueryWithOptions() args = %v, want %v", args, want)
+	}
+}
+
+func TestBuildQueryTextSearch(t *testing.T) {
+	selector := query.ResultSelector{
+		Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+			{Name: "description", Operator: filter.CompareOperatorTextContains, Value: "red shoes"},
+		}},
+		Sorting: &sorting.Request{Keys: []sorting.Key{{Column: sorting.TextScoreColumn}}},
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		wantSQL string
+		wantErr bool
+	}{
+		{
+			name:    "default configuration",
+			wantSQL: `WHERE to_tsvector('english', u.description) @@ plainto_tsquery('english', $1) ORDER BY ts_rank(to_tsvector('english', u.description), plainto_tsquery('english', $2)) DESC`,
+		},
+		{
+			name:    "configured",
+			options: Options{TextSearchConfig: "pg_catalog.simple"},
+			wantSQL: `WHERE to_tsvector('pg_catalog.simple', u.description) @@ plainto_tsquery('pg_catalog.simple', $1) ORDER BY ts_rank(to_tsvector('pg_catalog.simple', u.description), plainto_tsquery('pg_catalog.simple', $2)) DESC`,
+		},
+		{
+			name:    "invalid configuration",
+			options: Options{TextSearchConfig: "english'); DROP TABLE users; --"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := BuildQueryWithOptions(selector, testColumns, tt.options)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("BuildQueryWithOptions() = %q, want an error", sql)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildQueryWithOptions() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("BuildQueryWithOptions() sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if want := []any{"red shoes", "red shoes"}; !reflect.DeepEqual(args, want) {
+				t.Errorf("BuildQueryWithOptions() args = %v, want %v", args, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package query
+
+import (
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+)
+
+// AggregateSelector is a type that represents the selection criteria for an aggregation query.
+// Filter is a pointer to a filter.Request struct that specifies the records taking part in the aggregation.
+// Aggregation is a filter.Aggregation struct that specifies the metric to compute and how to group it.
+type AggregateSelector struct {
+	Filter      *filter.Request    `json:"filter" binding:"omitempty"`
+	Aggregation filter.Aggregation `json:"aggregation" binding:"required"`
+}
+
+// AggregateResult represents the value of an aggregation metric for one group of records.
+// The 'Group' field holds the value of every group by field of the group, keyed by field name. It is empty when no grouping was requested.
+// The 'Value' field holds the computed metric.
+type AggregateResult struct {
+	Group map[string]any `json:"group,omitempty"`
+	Value any            `json:"value"`
+}
+
+// AggregateResponse represents the response to an aggregation query, shaped like list query responses.
+type AggregateResponse = ResponseListWithMetadata[AggregateResult]
+
+// NewAggregateMetadata creates a new Metadata object echoing the filter and aggregation of the provided AggregateSelector.
+func NewAggregateMetadata(aggregateSelector AggregateSelector) Metadata {
+	aggregation := aggregateSelector.Aggregation
+	return Metadata{
+		Filter:      aggregateSelector.Filter,
+		Aggregation: &aggregation,
+	}
+}
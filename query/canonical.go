@@ -0,0 +1,92 @@
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+// Canonical returns a copy of the result selector in canonical form, so that selectors returning the same results
+// are equal, and marshal to the same JSON, however they were written:
+//   - the filter is in the canonical form of filter.Request.Canonical, and an empty filter is dropped
+//   - the sorting lists its keys only, with lower case directions, ascending when none was given
+//   - the paging holds the effective page index and size, the index being zero in cursor mode
+func (r ResultSelector) Canonical() ResultSelector {
+	var canonical ResultSelector
+
+	if r.Filter != nil && (len(r.Filter.Fields) > 0 || len(r.Filter.Groups) > 0) {
+		canonical.Filter = r.Filter.Canonical()
+	}
+
+	if keys := r.Sorting.SortKeys(); len(keys) > 0 {
+		canonical.Sorting = &sorting.Request{Keys: make([]sorting.Key, len(keys))}
+		for i, key := range keys {
+			direction := sorting.SortDirectionFromString(string(key.Direction))
+			switch {
+			case key.Column == sorting.TextScoreColumn:
+				// the direction of the text score is ignored
+				direction = sorting.NoDirection
+			case key.Direction == sorting.NoDirection:
+				direction = sorting.DirectionAscending
+			case direction == sorting.NoDirection:
+				// unsupported directions are kept, so that the selector stays invalid
+				direction = key.Direction
+			}
+			canonical.Sorting.Keys[i] = sorting.Key{Column: key.Column, Direction: direction}
+		}
+	}
+
+	if r.Paging != nil {
+		canonical.Paging = &paging.Request{
+			PageIndex: max(r.Paging.PageIndex, 0),
+			PageSize:  r.Paging.Limit(),
+			Cursor:    r.Paging.Cursor,
+		}
+		if canonical.Paging.Cursor != "" {
+			canonical.Paging.PageIndex = 0
+		}
+	}
+
+	return canonical
+}
+
+// Hash returns a stable hash of the canonical form of the result selector, as a hex encoded SHA-256 digest.
+// Selectors returning the same results have the same hash, which makes it suitable as a cache key.
+func (r ResultSelector) Hash() (string, error) {
+	return hashJSON(r.Canonical())
+}
+
+// ETag returns a strong entity tag for the response, derived from the hash of its JSON form,
+// to be sent in the ETag header and compared with the If-None-Match header of later requests.
+func (r ResponseListWithMetadata[T]) ETag() (string, error) {
+	return etag(r)
+}
+
+// ETag returns a strong entity tag for the response, derived from the hash of its JSON form,
+// to be sent in the ETag header and compared with the If-None-Match header of later requests.
+func (r ResponseWithMetadata[T]) ETag() (string, error) {
+	return etag(r)
+}
+
+// etag returns the quoted hash of the JSON form of value.
+func etag(value any) (string, error) {
+	hash, err := hashJSON(value)
+	if err != nil {
+		return "", err
+	}
+	return `"` + hash + `"`, nil
+}
+
+// hashJSON returns the hex encoded SHA-256 digest of the JSON form of value.
+func hashJSON(value any) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal value for hashing: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
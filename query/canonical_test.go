@@ -0,0 +1,115 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+func TestResultSelectorHash(t *testing.T) {
+	lagos := time.FixedZone("WAT", 60*60)
+	selector := func(fields ...filter.RequestField) ResultSelector {
+		return ResultSelector{
+			Filter:  &filter.Request{Operator: filter.LogicOperatorAnd, Fields: fields},
+			Sorting: &sorting.Request{Keys: []sorting.Key{{Column: "price", Direction: sorting.DirectionDescending}}},
+			Paging:  &paging.Request{PageIndex: 1, PageSize: 20},
+		}
+	}
+	base := selector(
+		filter.RequestField{Name: "city", Operator: filter.CompareOperatorContains, Value: []any{"Abuja", "Lagos"}},
+		filter.RequestField{Name: "createdAt", Operator: filter.CompareOperatorAfterDate, Value: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		filter.RequestField{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 12.0},
+	)
+
+	tests := []struct {
+		name      string
+		selector  ResultSelector
+		wantEqual bool
+	}{
+		{name: "same selector", selector: base, wantEqual: true},
+		{
+			name: "reordered fields, string slice, duplicates, int and time zone",
+			selector: selector(
+				filter.RequestField{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 12},
+				filter.RequestField{Name: "createdAt", Operator: filter.CompareOperatorAfterDate, Value: time.Date(2024, 3, 1, 13, 0, 0, 0, lagos)},
+				filter.RequestField{Name: "city", Operator: filter.CompareOperatorContains, Value: []string{"Lagos", "Abuja", "Lagos"}},
+			),
+			wantEqual: true,
+		},
+		{
+			name: "legacy sorting",
+			selector: ResultSelector{
+				Filter:  base.Filter,
+				Sorting: &sorting.Request{SortColumn: "price", SortDirection: "DESC"},
+				Paging:  &paging.Request{PageIndex: 1, PageSize: 20},
+			},
+			wantEqual: true,
+		},
+		{
+			name: "different value",
+			selector: selector(
+				filter.RequestField{Name: "city", Operator: filter.CompareOperatorContains, Value: []any{"Abuja"}},
+				filter.RequestField{Name: "createdAt", Operator: filter.CompareOperatorAfterDate, Value: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+				filter.RequestField{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 12.0},
+			),
+		},
+		{
+			name: "different date",
+			selector: selector(
+				filter.RequestField{Name: "city", Operator: filter.CompareOperatorContains, Value: []any{"Abuja", "Lagos"}},
+				filter.RequestField{Name: "createdAt", Operator: filter.CompareOperatorAfterDate, Value: time.Date(2024, 3, 1, 12, 0, 0, 0, lagos)},
+				filter.RequestField{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 12.0},
+			),
+		},
+		{
+			name:     "different sorting",
+			selector: ResultSelector{Filter: base.Filter, Sorting: &sorting.Request{Keys: []sorting.Key{{Column: "price"}}}, Paging: base.Paging},
+		},
+		{
+			name:     "different page",
+			selector: ResultSelector{Filter: base.Filter, Sorting: base.Sorting, Paging: &paging.Request{PageIndex: 2, PageSize: 20}},
+		},
+		{
+			name:     "default page size",
+			selector: ResultSelector{Filter: base.Filter, Sorting: base.Sorting, Paging: &paging.Request{PageIndex: 1}},
+		},
+		{
+			name:     "no filter",
+			selector: ResultSelector{Sorting: base.Sorting, Paging: base.Paging},
+		},
+	}
+
+	want, err := base.Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selector.Hash()
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if (got == want) != tt.wantEqual {
+				t.Errorf("Hash() = %s, base hash %s, want equal = %t", got, want, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestResultSelectorHashIgnoresEmptyParts(t *testing.T) {
+	empty, err := ResultSelector{}.Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	got, err := ResultSelector{Filter: &filter.Request{Operator: filter.LogicOperatorAnd}, Sorting: &sorting.Request{}}.Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if got != empty {
+		t.Errorf("Hash() of an empty filter and sorting = %s, want the hash of an empty selector %s", got, empty)
+	}
+}
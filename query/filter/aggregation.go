@@ -0,0 +1,53 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+)
+
+// Aggregation is a struct representing an aggregation request.
+// Metric is the aggregate computed over the values of Field, e.g. the sum of the order amounts.
+// Field is the name of the field the metric is computed on. For valueCount, the records where it is set are counted.
+// GroupBy is a slice of field names; when set, the metric is computed for every distinct combination of their values.
+type Aggregation struct {
+	Metric  AggregateMetric `json:"metric" binding:"required"`
+	Field   string          `json:"field" binding:"required"`
+	GroupBy []string        `json:"groupBy,omitempty"`
+}
+
+// Validate checks that the aggregation uses a known metric, names the field to aggregate with a valid field name,
+// and groups by distinct, plain field names.
+// It returns an errs.InvalidRequestError describing the first violation.
+func (a *Aggregation) Validate() error {
+	if a == nil {
+		return errs.Body(errs.InvalidRequestError, errors.New("aggregation is required"))
+	}
+
+	if !a.Metric.IsValid() {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("aggregation metric %q: %w", a.Metric, ErrInvalidAggregateMetric))
+	}
+
+	if a.Field == "" {
+		return errs.Body(errs.InvalidRequestError, errors.New("aggregation field is required"))
+	}
+	if err := validateName(a.Field); err != nil {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("aggregation field %q: %w", a.Field, err))
+	}
+
+	seen := make(map[string]bool, len(a.GroupBy))
+	for _, name := range a.GroupBy {
+		// group by names are used as keys of the group documents, so they cannot be paths
+		if name == "" || strings.Contains(name, ".") || validateName(name) != nil {
+			return errs.Body(errs.InvalidRequestError, fmt.Errorf("aggregation group by field %q is not allowed", name))
+		}
+		if seen[name] {
+			return errs.Body(errs.InvalidRequestError, fmt.Errorf("aggregation group by field %q is repeated", name))
+		}
+		seen[name] = true
+	}
+
+	return nil
+}
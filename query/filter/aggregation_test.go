@@ -0,0 +1,48 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+)
+
+func TestAggregationValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		aggregation *Aggregation
+		wantErr     bool
+	}{
+		{name: "sum", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "amount"}},
+		{name: "nested field", aggregation: &Aggregation{Metric: AggregateMetricAvg, Field: "order.amount", GroupBy: []string{"city", "status"}}},
+		{name: "nil", aggregation: nil, wantErr: true},
+		{name: "unknown metric", aggregation: &Aggregation{Metric: AggregateMetric("median"), Field: "amount"}, wantErr: true},
+		{name: "no field", aggregation: &Aggregation{Metric: AggregateMetricSum}, wantErr: true},
+		{name: "operator field", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "$where"}, wantErr: true},
+		{name: "nested operator field", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "order.$amount"}, wantErr: true},
+		{name: "empty field part", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "order."}, wantErr: true},
+		{name: "NUL in field", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "amount\x00"}, wantErr: true},
+		{name: "empty group by", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "amount", GroupBy: []string{""}}, wantErr: true},
+		{name: "operator group by", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "amount", GroupBy: []string{"$city"}}, wantErr: true},
+		{name: "nested group by", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "amount", GroupBy: []string{"address.city"}}, wantErr: true},
+		{name: "NUL in group by", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "amount", GroupBy: []string{"city\x00"}}, wantErr: true},
+		{name: "repeated group by", aggregation: &Aggregation{Metric: AggregateMetricSum, Field: "amount", GroupBy: []string{"city", "city"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.aggregation.Validate()
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var response *errs.Response
+			if !errors.As(err, &response) || response.ErrorCode != errs.InvalidRequestError {
+				t.Errorf("Validate() = %v, want an InvalidRequestError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// maxExactFloatInteger is the largest integer from which all integers can be represented exactly by a float64.
+const maxExactFloatInteger = 1 << 53
+
+// Canonical returns a copy of the request in canonical form, so that requests selecting the same records are equal
+// whatever the order of their fields and the Go types of their values:
+//   - numbers become float64, unless they are integers too large for it, dates UTC time.Time values, IP values their masked address or CIDR block,
+//     UUIDs and other fmt.Stringer values strings, and a missing exists value true
+//   - the values of contains, doesNotContain, isEqualTo and isNotEqualTo are sorted and deduplicated,
+//     and a single value is no longer wrapped in a list
+//   - fields and nested groups are sorted, and empty nested groups dropped
+//
+// The request is expected to be valid; values that do not have the shape of their operator are kept as they are.
+func (r *Request) Canonical() *Request {
+	if r == nil {
+		return nil
+	}
+
+	canonical := &Request{Operator: r.Operator}
+
+	if len(r.Fields) > 0 {
+		canonical.Fields = make([]RequestField, len(r.Fields))
+		for i, field := range r.Fields {
+			canonical.Fields[i] = RequestField{Name: field.Name, Operator: field.Operator, Value: canonicalValue(field.Operator, field.Value)}
+		}
+		slices.SortFunc(canonical.Fields, func(a, b RequestField) int {
+			return cmp.Or(
+				cmp.Compare(a.Name, b.Name),
+				cmp.Compare(a.Operator, b.Operator),
+				cmp.Compare(canonicalKey(a.Value), canonicalKey(b.Value)),
+			)
+		})
+	}
+
+	for i := range r.Groups {
+		group := r.Groups[i].Canonical()
+		if len(group.Fields) > 0 || len(group.Groups) > 0 {
+			canonical.Groups = append(canonical.Groups, *group)
+		}
+	}
+	slices.SortFunc(canonical.Groups, func(a, b Request) int {
+		return cmp.Compare(canonicalKey(a), canonicalKey(b))
+	})
+
+	return canonical
+}
+
+// canonicalValue returns the canonical form of the value of a field using operator.
+func canonicalValue(operator CompareOperator, value any) any {
+	switch operatorShapes[operator] {
+	case shapeNumber, shapeRating:
+		return canonicalScalar(value)
+
+	case shapeDate:
+		if t, err := ValueTime(value); err == nil {
+			return t.UTC()
+		}
+
+	case shapeDateRange:
+		if from, to, err := ValueTimeRange(value); err == nil {
+			return []any{from.UTC(), to.UTC()}
+		}
+
+	case shapeIP:
+		if prefix, err := ValueIPPrefix(value); err == nil {
+			if prefix.IsSingleIP() {
+				return prefix.Addr().String()
+			}
+			return prefix.String()
+		}
+
+	case shapeOptionalBool:
+		if value == nil {
+			return true
+		}
+
+	case shapeScalarOrList:
+		list, ok := ValueList(value)
+		if !ok {
+			return canonicalScalar(value)
+		}
+
+		values := make([]any, len(list))
+		for i, element := range list {
+			values[i] = canonicalScalar(element)
+		}
+		slices.SortFunc(values, func(a, b any) int {
+			return cmp.Compare(canonicalKey(a), canonicalKey(b))
+		})
+		values = slices.CompactFunc(values, func(a, b any) bool {
+			return canonicalKey(a) == canonicalKey(b)
+		})
+		if len(values) == 1 {
+			return values[0]
+		}
+		return values
+	}
+
+	return canonicalScalar(value)
+}
+
+// canonicalScalar returns the canonical form of a single value.
+func canonicalScalar(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC()
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return canonicalScalar(i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	// integers become float64 unless they are too large to be represented exactly
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanInt() && rv.Int() >= -maxExactFloatInteger && rv.Int() <= maxExactFloatInteger:
+		return float64(rv.Int())
+	case rv.CanUint() && rv.Uint() <= maxExactFloatInteger:
+		return float64(rv.Uint())
+	case rv.CanFloat():
+		return rv.Float()
+	}
+	return value
+}
+
+// canonicalKey returns the JSON form of a canonical value, used to order values of any type.
+func canonicalKey(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -0,0 +1,133 @@
+package filter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestCanonical(t *testing.T) {
+	lagos := time.FixedZone("WAT", 60*60)
+	id := uuid.MustParse("6f1c2b9e-8a44-4b6e-9d5e-2f0c6a7b1e3d")
+	and := func(fields ...RequestField) *Request {
+		return &Request{Operator: LogicOperatorAnd, Fields: fields}
+	}
+
+	tests := []struct {
+		name      string
+		a, b      *Request
+		wantEqual bool
+	}{
+		{
+			name:      "reordered fields",
+			a:         and(RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: "Lagos"}, RequestField{Name: "age", Operator: CompareOperatorIsGreaterThan, Value: 18}),
+			b:         and(RequestField{Name: "age", Operator: CompareOperatorIsGreaterThan, Value: 18}, RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: "Lagos"}),
+			wantEqual: true,
+		},
+		{
+			name:      "reordered groups",
+			a:         &Request{Operator: LogicOperatorOr, Groups: []Request{*and(RequestField{Name: "a", Operator: CompareOperatorIsEqualTo, Value: "1"}), *and(RequestField{Name: "b", Operator: CompareOperatorIsEqualTo, Value: "2"})}},
+			b:         &Request{Operator: LogicOperatorOr, Groups: []Request{*and(RequestField{Name: "b", Operator: CompareOperatorIsEqualTo, Value: "2"}), *and(RequestField{Name: "a", Operator: CompareOperatorIsEqualTo, Value: "1"}), {Operator: LogicOperatorAnd}}},
+			wantEqual: true,
+		},
+		{
+			name:      "string slice and any slice",
+			a:         and(RequestField{Name: "city", Operator: CompareOperatorContains, Value: []string{"Lagos", "Abuja"}}),
+			b:         and(RequestField{Name: "city", Operator: CompareOperatorContains, Value: []any{"Abuja", "Lagos"}}),
+			wantEqual: true,
+		},
+		{
+			name:      "duplicate values",
+			a:         and(RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: []any{"Lagos", "Abuja", "Lagos"}}),
+			b:         and(RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: []any{"Abuja", "Lagos"}}),
+			wantEqual: true,
+		},
+		{
+			name:      "single value list",
+			a:         and(RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: []any{"Lagos", "Lagos"}}),
+			b:         and(RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: "Lagos"}),
+			wantEqual: true,
+		},
+		{
+			name:      "int and float",
+			a:         and(RequestField{Name: "price", Operator: CompareOperatorIsNumberEqualTo, Value: 12}, RequestField{Name: "qty", Operator: CompareOperatorIsEqualTo, Value: []any{int64(1), uint8(2)}}),
+			b:         and(RequestField{Name: "price", Operator: CompareOperatorIsNumberEqualTo, Value: 12.0}, RequestField{Name: "qty", Operator: CompareOperatorIsEqualTo, Value: []any{json.Number("2"), 1.0}}),
+			wantEqual: true,
+		},
+		{
+			name:      "time zones",
+			a:         and(RequestField{Name: "createdAt", Operator: CompareOperatorBeforeDate, Value: "2024-03-01T13:00:00+01:00"}, RequestField{Name: "updatedAt", Operator: CompareOperatorIsGreaterThan, Value: time.Date(2024, 3, 1, 13, 0, 0, 0, lagos)}),
+			b:         and(RequestField{Name: "createdAt", Operator: CompareOperatorBeforeDate, Value: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}, RequestField{Name: "updatedAt", Operator: CompareOperatorIsGreaterThan, Value: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}),
+			wantEqual: true,
+		},
+		{
+			name:      "uuid and string",
+			a:         and(RequestField{Name: "id", Operator: CompareOperatorIsEqualTo, Value: id}),
+			b:         and(RequestField{Name: "id", Operator: CompareOperatorIsEqualTo, Value: id.String()}),
+			wantEqual: true,
+		},
+		{
+			name:      "missing exists value",
+			a:         and(RequestField{Name: "deletedAt", Operator: CompareOperatorExists}),
+			b:         and(RequestField{Name: "deletedAt", Operator: CompareOperatorExists, Value: true}),
+			wantEqual: true,
+		},
+		{
+			name: "different values",
+			a:    and(RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: "Lagos"}),
+			b:    and(RequestField{Name: "city", Operator: CompareOperatorIsEqualTo, Value: "Abuja"}),
+		},
+		{
+			name: "different operators",
+			a:    and(RequestField{Name: "price", Operator: CompareOperatorIsGreaterThan, Value: 12}),
+			b:    and(RequestField{Name: "price", Operator: CompareOperatorIsGreaterThanOrEqualTo, Value: 12}),
+		},
+		{
+			name: "different logic operators",
+			a:    and(RequestField{Name: "a", Operator: CompareOperatorIsEqualTo, Value: "1"}, RequestField{Name: "b", Operator: CompareOperatorIsEqualTo, Value: "2"}),
+			b:    &Request{Operator: LogicOperatorOr, Fields: and(RequestField{Name: "a", Operator: CompareOperatorIsEqualTo, Value: "1"}, RequestField{Name: "b", Operator: CompareOperatorIsEqualTo, Value: "2"}).Fields},
+		},
+		{
+			name: "string and number",
+			a:    and(RequestField{Name: "code", Operator: CompareOperatorIsEqualTo, Value: "12"}),
+			b:    and(RequestField{Name: "code", Operator: CompareOperatorIsEqualTo, Value: 12}),
+		},
+		{
+			name: "large integers",
+			a:    and(RequestField{Name: "id", Operator: CompareOperatorIsEqualTo, Value: int64(9007199254740993)}),
+			b:    and(RequestField{Name: "id", Operator: CompareOperatorIsEqualTo, Value: int64(9007199254740992)}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := tt.a.Canonical(), tt.b.Canonical()
+			if got := reflect.DeepEqual(a, b); got != tt.wantEqual {
+				t.Errorf("Canonical() equal = %t, want %t:\n%+v\n%+v", got, tt.wantEqual, a, b)
+			}
+			if got := canonicalKey(a) == canonicalKey(b); got != tt.wantEqual {
+				t.Errorf("Canonical() JSON equal = %t, want %t:\n%s\n%s", got, tt.wantEqual, canonicalKey(a), canonicalKey(b))
+			}
+		})
+	}
+}
+
+func TestRequestCanonicalKeepsRequest(t *testing.T) {
+	request := &Request{Operator: LogicOperatorAnd, Fields: []RequestField{
+		{Name: "city", Operator: CompareOperatorContains, Value: []any{"Lagos", "Abuja"}},
+		{Name: "age", Operator: CompareOperatorIsGreaterThan, Value: 18},
+	}}
+
+	request.Canonical()
+
+	want := &Request{Operator: LogicOperatorAnd, Fields: []RequestField{
+		{Name: "city", Operator: CompareOperatorContains, Value: []any{"Lagos", "Abuja"}},
+		{Name: "age", Operator: CompareOperatorIsGreaterThan, Value: 18},
+	}}
+	if !reflect.DeepEqual(request, want) {
+		t.Errorf("Canonical() changed the request to %+v", request)
+	}
+}
@@ -0,0 +1,163 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// Coerce converts a raw value, as decoded from JSON or a query string, into the Go type of the given control type:
+//   - bool: bool, from a boolean or "true"/"false"
+//   - enum, string, autocomplete: string
+//   - float: float64, from any number or numeric string
+//   - integer: int64, from any integer, or number or numeric string without a fractional part; integers are read exactly
+//   - dateTime: time.Time, from an RFC 3339 timestamp or a 2006-01-02 date
+//   - uuid: uuid.UUID, from its string form
+//
+// Lists are coerced element by element into a []any. nil is returned unchanged.
+func Coerce(control ControlType, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	list, ok := ValueList(value)
+	if !ok {
+		return coerceScalar(control, value)
+	}
+
+	coerced := make([]any, len(list))
+	for i, element := range list {
+		c, err := coerceScalar(control, element)
+		if err != nil {
+			return nil, fmt.Errorf("value at index %d: %w", i, err)
+		}
+		coerced[i] = c
+	}
+	return coerced, nil
+}
+
+// coerceScalar converts a single raw value into the Go type of the given control type.
+func coerceScalar(control ControlType, value any) (any, error) {
+	switch control {
+	case ControlTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid boolean", v)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected a boolean value, got %T", value)
+
+	case ControlTypeEnum, ControlTypeString, ControlTypeAutocomplete:
+		s, ok := ValueString(value)
+		if !ok {
+			return nil, fmt.Errorf("expected a string value, got %T", value)
+		}
+		return s, nil
+
+	case ControlTypeFloat:
+		return coerceFloat(value)
+
+	case ControlTypeInteger:
+		return coerceInteger(value)
+
+	case ControlTypeDateTime:
+		return ValueTime(value)
+
+	case ControlTypeUuid:
+		if id, ok := value.(uuid.UUID); ok {
+			return id, nil
+		}
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a uuid value, got %T", value)
+		}
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid uuid: %w", s, err)
+		}
+		return id, nil
+
+	default:
+		return nil, fmt.Errorf("control type %q is not supported", control)
+	}
+}
+
+// coerceInteger converts an integer, a json.Number, a numeric string or a number without a fractional part into an int64.
+// Integers written without a fractional part are parsed exactly; other numbers go through float64,
+// so they are only accepted as long as a float64 holds them exactly.
+func coerceInteger(value any) (int64, error) {
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanInt():
+		return rv.Int(), nil
+	case rv.CanUint():
+		if rv.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("%v is out of the range of integers", value)
+		}
+		return int64(rv.Uint()), nil
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	}
+	if s != "" {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			return i, nil
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("%q is out of the range of integers", s)
+		}
+	}
+
+	f, err := coerceFloat(value)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("%v is not a valid integer", value)
+	}
+	// from 2^53 on, a float64 may have rounded the integer the value was written as
+	if math.Abs(f) >= 1<<53 {
+		return 0, fmt.Errorf("%v is too large to be read as an exact integer", value)
+	}
+	return int64(f), nil
+}
+
+// coerceFloat converts a number, a json.Number or a numeric string into a float64.
+func coerceFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid number", v)
+		}
+		return f, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid number", v)
+		}
+		return f, nil
+	}
+
+	f, ok := ValueNumber(value)
+	if !ok {
+		return 0, fmt.Errorf("expected a number value, got %T", value)
+	}
+	return f, nil
+}
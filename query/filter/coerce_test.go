@@ -0,0 +1,71 @@
+package filter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCoerce(t *testing.T) {
+	id := uuid.MustParse("6f1c2b9e-8a44-4b6e-9d5e-2f0c6a7b1e3d")
+
+	tests := []struct {
+		name    string
+		control ControlType
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{name: "nil", control: ControlTypeInteger, value: nil, want: nil},
+		{name: "bool", control: ControlTypeBool, value: true, want: true},
+		{name: "bool string", control: ControlTypeBool, value: "false", want: false},
+		{name: "invalid bool", control: ControlTypeBool, value: "yes", wantErr: true},
+		{name: "string", control: ControlTypeString, value: "abc", want: "abc"},
+		{name: "enum of a number", control: ControlTypeEnum, value: 1.5, wantErr: true},
+		{name: "float", control: ControlTypeFloat, value: 4, want: 4.0},
+		{name: "float string", control: ControlTypeFloat, value: "4.5", want: 4.5},
+		{name: "float json number", control: ControlTypeFloat, value: json.Number("4.5"), want: 4.5},
+		{name: "invalid float", control: ControlTypeFloat, value: "four", wantErr: true},
+		{name: "integer", control: ControlTypeInteger, value: 4, want: int64(4)},
+		{name: "unsigned integer", control: ControlTypeInteger, value: uint8(4), want: int64(4)},
+		{name: "unsigned integer out of range", control: ControlTypeInteger, value: uint64(1 << 63), wantErr: true},
+		{name: "whole float", control: ControlTypeInteger, value: 4.0, want: int64(4)},
+		{name: "whole float string", control: ControlTypeInteger, value: "4.0", want: int64(4)},
+		{name: "fractional float", control: ControlTypeInteger, value: 4.5, wantErr: true},
+		{name: "fractional string", control: ControlTypeInteger, value: "4.5", wantErr: true},
+		{name: "integer string above 2^53", control: ControlTypeInteger, value: "9007199254740993", want: int64(9007199254740993)},
+		{name: "json number above 2^53", control: ControlTypeInteger, value: json.Number("9007199254740993"), want: int64(9007199254740993)},
+		{name: "negative integer string", control: ControlTypeInteger, value: "-9223372036854775808", want: int64(-9223372036854775808)},
+		{name: "integer string out of range", control: ControlTypeInteger, value: "9223372036854775808", wantErr: true},
+		{name: "float above 2^53", control: ControlTypeInteger, value: "9.007199254740993e15", wantErr: true},
+		{name: "date", control: ControlTypeDateTime, value: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "timestamp", control: ControlTypeDateTime, value: "2024-03-01T10:00:00Z", want: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{name: "invalid date", control: ControlTypeDateTime, value: "01/03/2024", wantErr: true},
+		{name: "uuid", control: ControlTypeUuid, value: id.String(), want: id},
+		{name: "invalid uuid", control: ControlTypeUuid, value: "abc", wantErr: true},
+		{name: "list", control: ControlTypeInteger, value: []any{"1", 2.0, json.Number("3")}, want: []any{int64(1), int64(2), int64(3)}},
+		{name: "invalid list element", control: ControlTypeInteger, value: []any{1, "two"}, wantErr: true},
+		{name: "unsupported control type", control: ControlType("color"), value: "red", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Coerce(tt.control, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Coerce(%v) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Coerce(%v) error = %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Coerce(%v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
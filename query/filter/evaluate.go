@@ -0,0 +1,358 @@
+package filter
+
+import (
+	"cmp"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Match reports whether record satisfies the filter request and its nested groups.
+// record may be a struct, a map with string keys, or a pointer to either; fields are resolved with Resolve.
+// Operators behave like their MongoDB translation: a condition on a list field holds if it holds for any element,
+// negated conditions hold for missing fields, and exists holds for fields set to nil. An empty request matches every record.
+// Rating operators use the scale of their field in scales, if given, or DefaultRatingScale.
+// The request is validated first and its errs.InvalidRequestError returned if it is invalid.
+func (r *Request) Match(record any, scales ...RatingScales) (bool, error) {
+	if r == nil {
+		return true, nil
+	}
+
+	ratingScales := firstRatingScales(scales)
+	if err := r.Validate(ratingScales); err != nil {
+		return false, err
+	}
+
+	return r.match(record, ratingScales), nil
+}
+
+// match evaluates a validated request against record.
+func (r *Request) match(record any, scales RatingScales) bool {
+	conditions := len(r.Fields) + len(r.Groups)
+	if conditions == 0 {
+		return true
+	}
+
+	results := make([]bool, 0, conditions)
+	for _, field := range r.Fields {
+		value, found := Resolve(record, field.Name)
+		results = append(results, field.match(value, found, scales.Scale(field.Name)))
+	}
+	for i := range r.Groups {
+		results = append(results, r.Groups[i].match(record, scales))
+	}
+
+	if r.Operator == LogicOperatorOr {
+		for _, result := range results {
+			if result {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, result := range results {
+		if !result {
+			return false
+		}
+	}
+	return true
+}
+
+// match evaluates the field condition against the resolved value of the record, rating on scale.
+func (f RequestField) match(value any, found bool, scale RatingScale) bool {
+	switch f.Operator {
+	case CompareOperatorExists:
+		exists, ok := ValueBool(f.Value)
+		if !ok {
+			exists = true
+		}
+		// like MongoDB's $exists, a field holding null exists
+		return found == exists
+
+	case CompareOperatorDoesNotBeginWith:
+		return !RequestField{Operator: CompareOperatorBeginsWith, Value: f.Value}.match(value, found, scale)
+
+	case CompareOperatorDoesNotContain, CompareOperatorIsNotEqualTo:
+		return !RequestField{Operator: CompareOperatorIsEqualTo, Value: f.Value}.match(value, found, scale)
+
+	case CompareOperatorIsNumberNotEqualTo, CompareOperatorIsStringNotEqualTo:
+		return !RequestField{Operator: CompareOperatorIsEqualTo, Value: f.Value}.match(value, found, scale)
+
+	case CompareOperatorIsIpNotEqualTo:
+		return !RequestField{Operator: CompareOperatorIsIpEqualTo, Value: f.Value}.match(value, found, scale)
+
+	case CompareOperatorIsNotEqualToRating:
+		return !RequestField{Operator: CompareOperatorIsEqualToRating, Value: f.Value}.match(value, found, scale)
+	}
+
+	if !found || value == nil {
+		return false
+	}
+
+	// a condition on a list holds if it holds for any of its elements
+	if elements, ok := ValueList(value); ok {
+		for _, element := range elements {
+			if f.matchScalar(element, scale) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return f.matchScalar(value, scale)
+}
+
+// matchScalar evaluates the field condition against a single value of the record, rating on scale.
+func (f RequestField) matchScalar(value any, scale RatingScale) bool {
+	switch f.Operator {
+	case CompareOperatorBeginsWith:
+		s, ok := ValueString(value)
+		prefix, _ := ValueString(f.Value)
+		return ok && strings.HasPrefix(s, prefix)
+
+	case CompareOperatorTextContains:
+		s, ok := ValueString(value)
+		if !ok {
+			return false
+		}
+		text, _ := ValueString(f.Value)
+		s = strings.ToLower(s)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			if !strings.Contains(s, word) {
+				return false
+			}
+		}
+		return true
+
+	case CompareOperatorContains, CompareOperatorIsEqualTo, CompareOperatorIsNumberEqualTo,
+		CompareOperatorIsStringEqualTo:
+		candidates, ok := ValueList(f.Value)
+		if !ok {
+			candidates = []any{f.Value}
+		}
+		for _, candidate := range candidates {
+			if Equal(value, candidate) {
+				return true
+			}
+		}
+		return false
+
+	case CompareOperatorIsIpEqualTo:
+		// values that are not IP addresses never match
+		addr, err := ValueIP(value)
+		if err != nil {
+			return false
+		}
+		prefix, _ := ValueIPPrefix(f.Value)
+		return prefix.Contains(addr)
+
+	case CompareOperatorIsStringCaseInsensitiveEqualTo:
+		s, ok := ValueString(value)
+		expected, _ := ValueString(f.Value)
+		return ok && strings.EqualFold(s, expected)
+
+	case CompareOperatorIsGreaterThan:
+		c, ok := Compare(value, f.Value)
+		return ok && c > 0
+	case CompareOperatorIsGreaterThanOrEqualTo:
+		c, ok := Compare(value, f.Value)
+		return ok && c >= 0
+	case CompareOperatorIsLessThan:
+		c, ok := Compare(value, f.Value)
+		return ok && c < 0
+	case CompareOperatorIsLessThanOrEqualTo:
+		c, ok := Compare(value, f.Value)
+		return ok && c <= 0
+
+	case CompareOperatorIsEqualToRating, CompareOperatorIsGreaterThanRating, CompareOperatorIsGreaterThanOrEqualToRating,
+		CompareOperatorIsLessThanRating, CompareOperatorIsLessThanOrEqualToRating:
+		rating, ok := ValueNumber(value)
+		if !ok {
+			return false
+		}
+		stars, _ := ValueNumber(f.Value)
+		from, to, err := scale.Range(f.Operator, stars)
+		return err == nil && from <= rating && rating < to
+
+	case CompareOperatorBeforeDate, CompareOperatorAfterDate:
+		t, err := ValueTime(value)
+		if err != nil {
+			return false
+		}
+		date, _ := ValueTime(f.Value)
+		if f.Operator == CompareOperatorBeforeDate {
+			return t.Before(date)
+		}
+		return t.After(date)
+
+	case CompareOperatorBetweenDates:
+		t, err := ValueTime(value)
+		if err != nil {
+			return false
+		}
+		from, to, _ := ValueTimeRange(f.Value)
+		return !t.Before(from) && !t.After(to)
+
+	default:
+		return false
+	}
+}
+
+// Equal reports whether two values are equal, comparing numbers by value whatever their Go type,
+// times by instant and values implementing fmt.Stringer, such as uuid.UUID, by their string form.
+func Equal(a, b any) bool {
+	if c, ok := Compare(a, b); ok {
+		return c == 0
+	}
+	if sa, ok := ValueString(a); ok {
+		if sb, ok := ValueString(b); ok {
+			return sa == sb
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Compare orders two values of the same kind: numbers, times, strings or booleans.
+// The second return value is false if the values cannot be ordered against each other.
+func Compare(a, b any) (int, bool) {
+	if na, ok := ValueNumber(a); ok {
+		nb, ok := ValueNumber(b)
+		return cmp.Compare(na, nb), ok
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		tb, err := ValueTime(b)
+		return ta.Compare(tb), err == nil
+	}
+
+	if ba, ok := a.(bool); ok {
+		bb, ok := b.(bool)
+		if !ok || ba == bb {
+			return 0, ok
+		}
+		if bb {
+			return -1, true
+		}
+		return 1, true
+	}
+
+	if sa, ok := a.(string); ok {
+		// strings holding dates are compared to times as dates
+		if tb, ok := b.(time.Time); ok {
+			ta, err := ValueTime(sa)
+			return ta.Compare(tb), err == nil
+		}
+		sb, ok := b.(string)
+		return strings.Compare(sa, sb), ok
+	}
+
+	return 0, false
+}
+
+// Resolve returns the value found at a dotted path, such as "address.city", within record.
+// Struct fields are looked up by their json tag name, or their Go name when they have none, including the fields of
+// embedded structs. Maps are looked up by key, pointers and interfaces are followed, and numeric path segments index lists.
+// When a segment other than an index meets a list, it is resolved in every element and the results are returned as a []any.
+// The second return value reports whether the path was found.
+func Resolve(record any, path string) (any, bool) {
+	return resolve(reflect.ValueOf(record), strings.Split(path, "."))
+}
+
+// resolve follows the path segments from v.
+func resolve(v reflect.Value, segments []string) (any, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, len(segments) == 0
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, false
+	}
+
+	if len(segments) == 0 {
+		return v.Interface(), true
+	}
+
+	segment := segments[0]
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := structField(v, segment)
+		if !ok {
+			return nil, false
+		}
+		return resolve(field, segments[1:])
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		element := v.MapIndex(reflect.ValueOf(segment).Convert(v.Type().Key()))
+		if !element.IsValid() {
+			return nil, false
+		}
+		return resolve(element, segments[1:])
+
+	case reflect.Slice, reflect.Array:
+		if index, err := strconv.Atoi(segment); err == nil {
+			if index < 0 || index >= v.Len() {
+				return nil, false
+			}
+			return resolve(v.Index(index), segments[1:])
+		}
+
+		var values []any
+		for i := 0; i < v.Len(); i++ {
+			if value, ok := resolve(v.Index(i), segments); ok {
+				if nested, ok := value.([]any); ok {
+					values = append(values, nested...)
+				} else {
+					values = append(values, value)
+				}
+			}
+		}
+		return values, len(values) > 0
+
+	default:
+		return nil, false
+	}
+}
+
+// structField returns the field of the struct v whose json name is name, searching embedded structs as well.
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tagName == "-" {
+			continue
+		}
+
+		if tagName == "" && field.Anonymous {
+			embedded := v.Field(i)
+			for embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					break
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found, ok := structField(embedded, name); ok {
+					return found, true
+				}
+			}
+			continue
+		}
+
+		if tagName == name || (tagName == "" && field.Name == name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
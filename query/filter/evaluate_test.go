@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"testing"
+)
+
+type evaluateTestRecord struct {
+	Name    string            `json:"name"`
+	Email   *string           `json:"email"`
+	Tags    []string          `json:"tags"`
+	Address map[string]string `json:"address"`
+}
+
+func TestRequestFieldMatch(t *testing.T) {
+	email := "jo@example.com"
+	withEmail := evaluateTestRecord{Name: "Jo", Email: &email, Tags: []string{"new", "vip"}, Address: map[string]string{"city": "Lagos"}}
+	withoutEmail := evaluateTestRecord{Name: "Ann"}
+	document := map[string]any{"name": "Ann", "email": nil}
+
+	tests := []struct {
+		name   string
+		field  RequestField
+		record any
+		want   bool
+	}{
+		{name: "exists on set field", field: RequestField{Name: "email", Operator: CompareOperatorExists}, record: withEmail, want: true},
+		{name: "exists on nil field", field: RequestField{Name: "email", Operator: CompareOperatorExists}, record: withoutEmail, want: true},
+		{name: "exists on null document value", field: RequestField{Name: "email", Operator: CompareOperatorExists, Value: true}, record: document, want: true},
+		{name: "exists on missing document value", field: RequestField{Name: "phone", Operator: CompareOperatorExists}, record: document, want: false},
+		{name: "not exists on null document value", field: RequestField{Name: "email", Operator: CompareOperatorExists, Value: false}, record: document, want: false},
+		{name: "not exists on missing document value", field: RequestField{Name: "phone", Operator: CompareOperatorExists, Value: false}, record: document, want: true},
+		{name: "equality on nil field", field: RequestField{Name: "email", Operator: CompareOperatorIsEqualTo, Value: "x"}, record: withoutEmail, want: false},
+		{name: "negation on nil field", field: RequestField{Name: "email", Operator: CompareOperatorIsNotEqualTo, Value: "x"}, record: withoutEmail, want: true},
+		{name: "any list element", field: RequestField{Name: "tags", Operator: CompareOperatorIsEqualTo, Value: "vip"}, record: withEmail, want: true},
+		{name: "nested map value", field: RequestField{Name: "address.city", Operator: CompareOperatorBeginsWith, Value: "La"}, record: withEmail, want: true},
+		{name: "IP in IPv4 block", field: RequestField{Name: "ip", Operator: CompareOperatorIsIpEqualTo, Value: "10.0.0.0/8"}, record: map[string]any{"ip": "::ffff:10.1.2.3"}, want: true},
+		{name: "stored IP in IPv4 block", field: RequestField{Name: "ip", Operator: CompareOperatorIsIpEqualTo, Value: "10.0.0.0/8"}, record: map[string]any{"ip": []byte{10, 1, 2, 3}}, want: true},
+		{name: "IP in IPv6 block", field: RequestField{Name: "ip", Operator: CompareOperatorIsIpEqualTo, Value: "2001:db8::/32"}, record: map[string]any{"ip": "2001:0db8:0000::1"}, want: true},
+		{name: "IPv4 not in IPv6 block", field: RequestField{Name: "ip", Operator: CompareOperatorIsIpNotEqualTo, Value: "::/0"}, record: map[string]any{"ip": "10.1.2.3"}, want: true},
+		{name: "does not begin with", field: RequestField{Name: "name", Operator: CompareOperatorDoesNotBeginWith, Value: "J"}, record: withEmail, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &Request{Operator: LogicOperatorAnd, Fields: []RequestField{tt.field}}
+			got, err := request.Match(tt.record)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+)
+
+// RatingRounding tells how stored ratings are rounded to the star values of a RatingScale.
+type RatingRounding int
+
+const (
+	// RatingRoundHalfUp rounds ratings to the nearest star value, halves rounding up: 4 stars holds the ratings in [3.5, 4.5).
+	RatingRoundHalfUp RatingRounding = iota
+	// RatingRoundDown truncates ratings to the star value below them: 4 stars holds the ratings in [4, 5).
+	RatingRoundDown
+)
+
+// RatingScale describes the star values the rating operators accept and the stored ratings each of them holds.
+//
+// Fields:
+// - Min, Max: the lowest and highest star values, e.g. 1 and 5
+// - Step: the difference between two consecutive star values, e.g. 1 for whole stars or 0.5 for half stars
+// - Rounding: how stored ratings, which may hold any value such as an average of reviews, are rounded to star values
+type RatingScale struct {
+	Min      float64
+	Max      float64
+	Step     float64
+	Rounding RatingRounding
+}
+
+// DefaultRatingScale is the scale of the rating operators for fields without a scale of their own in RatingScales.
+var DefaultRatingScale = RatingScale{Min: 1, Max: 5, Step: 1, Rounding: RatingRoundHalfUp}
+
+// RatingScales maps field names to the scale their ratings use, for the fields not rated on DefaultRatingScale.
+// It is given to Request.Validate, Request.Match and the query builders, and built by Schema.RatingScales
+// from the options declaring a scale.
+type RatingScales map[string]RatingScale
+
+// Scale returns the rating scale of the named field: its own scale if it has one, DefaultRatingScale otherwise.
+func (s RatingScales) Scale(name string) RatingScale {
+	if scale, ok := s[name]; ok {
+		return scale
+	}
+	return DefaultRatingScale
+}
+
+// firstRatingScales returns the first of scales, or nil if there are none.
+func firstRatingScales(scales []RatingScales) RatingScales {
+	if len(scales) == 0 {
+		return nil
+	}
+	return scales[0]
+}
+
+// Validate checks that stars is one of the star values of the scale.
+func (s RatingScale) Validate(stars float64) error {
+	if s.Step <= 0 {
+		return fmt.Errorf("rating scale step must be positive, got %v", s.Step)
+	}
+	if stars < s.Min || stars > s.Max {
+		return fmt.Errorf("rating %v is outside of the scale %v to %v", stars, s.Min, s.Max)
+	}
+
+	steps := (stars - s.Min) / s.Step
+	if math.Abs(steps-math.Round(steps)) > 1e-9 {
+		return fmt.Errorf("rating %v is not a multiple of %v stars", stars, s.Step)
+	}
+
+	return nil
+}
+
+// Range returns the stored ratings selected by a rating operator and star value as the half-open range [from, to);
+// unbounded sides are returned as infinities. Every star value holds the ratings rounding to it, so with the default scale:
+//   - isEqualToRating 4 selects [3.5, 4.5), and isNotEqualToRating 4 the ratings outside of that same range
+//   - isGreaterThanRating 4 selects [4.5, +Inf) and isGreaterThanOrEqualToRating 4 selects [3.5, +Inf)
+//   - isLessThanRating 4 selects (-Inf, 3.5) and isLessThanOrEqualToRating 4 selects (-Inf, 4.5)
+//
+// An error is returned if the operator is not a rating operator or stars is not a star value of the scale.
+func (s RatingScale) Range(operator CompareOperator, stars float64) (from, to float64, err error) {
+	if err := s.Validate(stars); err != nil {
+		return 0, 0, err
+	}
+
+	lower, upper := stars, stars+s.Step
+	if s.Rounding == RatingRoundHalfUp {
+		lower, upper = stars-s.Step/2, stars+s.Step/2
+	}
+
+	switch operator {
+	case CompareOperatorIsEqualToRating, CompareOperatorIsNotEqualToRating:
+		return lower, upper, nil
+	case CompareOperatorIsGreaterThanRating:
+		return upper, math.Inf(1), nil
+	case CompareOperatorIsGreaterThanOrEqualToRating:
+		return lower, math.Inf(1), nil
+	case CompareOperatorIsLessThanRating:
+		return math.Inf(-1), lower, nil
+	case CompareOperatorIsLessThanOrEqualToRating:
+		return math.Inf(-1), upper, nil
+	default:
+		return 0, 0, fmt.Errorf("operator %s is not a rating operator", operator)
+	}
+}
@@ -0,0 +1,90 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRatingScaleRange(t *testing.T) {
+	halfStars := RatingScale{Min: 0.5, Max: 5, Step: 0.5, Rounding: RatingRoundHalfUp}
+	roundDown := RatingScale{Min: 1, Max: 5, Step: 1, Rounding: RatingRoundDown}
+
+	tests := []struct {
+		name     string
+		scale    RatingScale
+		operator CompareOperator
+		stars    float64
+		wantFrom float64
+		wantTo   float64
+		wantErr  bool
+	}{
+		{name: "equal", scale: DefaultRatingScale, operator: CompareOperatorIsEqualToRating, stars: 4, wantFrom: 3.5, wantTo: 4.5},
+		{name: "not equal", scale: DefaultRatingScale, operator: CompareOperatorIsNotEqualToRating, stars: 4, wantFrom: 3.5, wantTo: 4.5},
+		{name: "greater than", scale: DefaultRatingScale, operator: CompareOperatorIsGreaterThanRating, stars: 4, wantFrom: 4.5, wantTo: math.Inf(1)},
+		{name: "greater than or equal", scale: DefaultRatingScale, operator: CompareOperatorIsGreaterThanOrEqualToRating, stars: 4, wantFrom: 3.5, wantTo: math.Inf(1)},
+		{name: "less than", scale: DefaultRatingScale, operator: CompareOperatorIsLessThanRating, stars: 4, wantFrom: math.Inf(-1), wantTo: 3.5},
+		{name: "less than or equal", scale: DefaultRatingScale, operator: CompareOperatorIsLessThanOrEqualToRating, stars: 4, wantFrom: math.Inf(-1), wantTo: 4.5},
+		{name: "half stars", scale: halfStars, operator: CompareOperatorIsEqualToRating, stars: 3.5, wantFrom: 3.25, wantTo: 3.75},
+		{name: "round down", scale: roundDown, operator: CompareOperatorIsEqualToRating, stars: 4, wantFrom: 4, wantTo: 5},
+		{name: "round down greater than", scale: roundDown, operator: CompareOperatorIsGreaterThanRating, stars: 4, wantFrom: 5, wantTo: math.Inf(1)},
+		{name: "above the scale", scale: DefaultRatingScale, operator: CompareOperatorIsEqualToRating, stars: 6, wantErr: true},
+		{name: "below the scale", scale: DefaultRatingScale, operator: CompareOperatorIsEqualToRating, stars: 0, wantErr: true},
+		{name: "not a star value", scale: DefaultRatingScale, operator: CompareOperatorIsEqualToRating, stars: 3.5, wantErr: true},
+		{name: "not a rating operator", scale: DefaultRatingScale, operator: CompareOperatorIsEqualTo, stars: 4, wantErr: true},
+		{name: "no step", scale: RatingScale{Min: 1, Max: 5}, operator: CompareOperatorIsEqualToRating, stars: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := tt.scale.Range(tt.operator, tt.stars)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Range() = [%v, %v), want an error", from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Range() error = %v", err)
+			}
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("Range() = [%v, %v), want [%v, %v)", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestRatingScalesPerField(t *testing.T) {
+	scales := RatingScales{"score": {Min: 0, Max: 10, Step: 1, Rounding: RatingRoundDown}}
+
+	if got := scales.Scale("stars"); got != DefaultRatingScale {
+		t.Errorf("Scale(stars) = %+v, want DefaultRatingScale", got)
+	}
+
+	request := &Request{Operator: LogicOperatorAnd, Fields: []RequestField{
+		{Name: "score", Operator: CompareOperatorIsGreaterThanOrEqualToRating, Value: 8},
+	}}
+	if err := request.Validate(); err == nil {
+		t.Errorf("Validate() without scales accepted 8 stars on the default scale")
+	}
+	if err := request.Validate(scales); err != nil {
+		t.Fatalf("Validate(scales) error = %v", err)
+	}
+
+	tests := []struct {
+		score float64
+		want  bool
+	}{
+		{score: 7.9, want: false},
+		{score: 8, want: true},
+		{score: 9.5, want: true},
+	}
+	for _, tt := range tests {
+		got, err := request.Match(map[string]any{"score": tt.score}, scales)
+		if err != nil {
+			t.Fatalf("Match() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Match(score %v) = %t, want %t", tt.score, got, tt.want)
+		}
+	}
+}
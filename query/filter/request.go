@@ -1,11 +1,17 @@
 package filter
 
+// MaxGroupDepth is the deepest level of nested groups a Request may contain.
+const MaxGroupDepth = 8
+
 // Request is a struct representing a filter request.
 // Operator is the logic operator used for the request.
 // Fields is a slice of RequestField, representing the fields to be used for the filtering.
+// Groups is a slice of nested Request, each evaluated on its own and combined with Fields using Operator.
+// It allows expressing conditions such as "status = active AND (city = Lagos OR city = Abuja)".
 type Request struct {
 	Operator LogicOperator  `json:"operator" binding:"required"`
 	Fields   []RequestField `json:"fields" binding:"dive"`
+	Groups   []Request      `json:"groups,omitempty" binding:"omitempty,dive"`
 }
 
 // RequestOption configures a field for validation
@@ -15,12 +21,14 @@ type Request struct {
 // Operators: The list of comparison operators for the option
 // Values: The possible values for the option
 // MultiSelect: Indicates whether the option supports multiple selections
+// Rating: The scale of the rating operators on the option, DefaultRatingScale when nil
 type RequestOption struct {
 	Name        ReadableValue[string]
 	Control     RequestOptionType
 	Operators   []ReadableValue[CompareOperator]
 	Values      []string
 	MultiSelect bool
+	Rating      *RatingScale `json:",omitempty"`
 }
 
 // ReadableValue is a generic type that represents a human-readable value with a corresponding backend value.
@@ -34,7 +42,7 @@ type ReadableValue[T any] struct {
 
 // RequestOptionType configures the type of control for a field in a request option.
 type RequestOptionType struct {
-	Type ControlType `json:"type" enums:"string,float,integer,enum,bool"`
+	Type ControlType `json:"type" enums:"bool,enum,float,integer,string,dateTime,uuid,autocomplete"`
 }
 
 // RequestField represents a field in a request
@@ -47,3 +55,21 @@ type RequestField struct {
 	// Value can be a list of values or a value
 	Value any `json:"value" binding:"required"`
 }
+
+// FieldsWithOperator returns the fields of the request and of its nested groups that use the given operator.
+func (r *Request) FieldsWithOperator(operator CompareOperator) []RequestField {
+	if r == nil {
+		return nil
+	}
+
+	var fields []RequestField
+	for _, field := range r.Fields {
+		if field.Operator == operator {
+			fields = append(fields, field)
+		}
+	}
+	for i := range r.Groups {
+		fields = append(fields, r.Groups[i].FieldsWithOperator(operator)...)
+	}
+	return fields
+}
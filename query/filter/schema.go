@@ -0,0 +1,264 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+)
+
+// Schema describes the fields a service accepts in its filter requests.
+// It is declared once from the service's RequestOptions, validates incoming requests against them
+// and marshals to JSON as the list of options, so that frontends can render the matching filter controls.
+type Schema struct {
+	options []RequestOption
+	byName  map[string]RequestOption
+}
+
+// NewSchema creates a Schema from the given options.
+// It returns an error if an option has no name, a name is declared twice, a control type or operator is unknown,
+// an enum option does not list its values, or a rating scale has no positive step or a minimum above its maximum.
+func NewSchema(options ...RequestOption) (*Schema, error) {
+	schema := &Schema{
+		options: make([]RequestOption, 0, len(options)),
+		byName:  make(map[string]RequestOption, len(options)),
+	}
+
+	for _, option := range options {
+		name := option.Name.Value
+		if name == "" {
+			return nil, errors.New("filter option name is required")
+		}
+		if _, ok := schema.byName[name]; ok {
+			return nil, fmt.Errorf("filter option %q is declared twice", name)
+		}
+		if !option.Control.Type.IsValid() {
+			return nil, fmt.Errorf("filter option %q: %w", name, ErrInvalidControlType)
+		}
+		for _, operator := range option.Operators {
+			if !operator.Value.IsValid() {
+				return nil, fmt.Errorf("filter option %q: operator %q: %w", name, operator.Value, ErrInvalidCompareOperator)
+			}
+		}
+		if option.Control.Type == ControlTypeEnum && len(option.Values) == 0 {
+			return nil, fmt.Errorf("filter option %q: enum options must list their values", name)
+		}
+		if option.Rating != nil {
+			if option.Rating.Min > option.Rating.Max {
+				return nil, fmt.Errorf("filter option %q: rating scale minimum %v is above its maximum %v", name, option.Rating.Min, option.Rating.Max)
+			}
+			if err := option.Rating.Validate(option.Rating.Min); err != nil {
+				return nil, fmt.Errorf("filter option %q: %w", name, err)
+			}
+		}
+
+		schema.options = append(schema.options, option)
+		schema.byName[name] = option
+	}
+
+	return schema, nil
+}
+
+// Options returns the options of the schema in declaration order.
+func (s *Schema) Options() []RequestOption {
+	return slices.Clone(s.options)
+}
+
+// Option returns the option declared for the given field name.
+func (s *Schema) Option(name string) (RequestOption, bool) {
+	option, ok := s.byName[name]
+	return option, ok
+}
+
+// RatingScales returns the rating scales declared by the options, to be given to the query builders
+// and to Request.Match along with the requests the schema validated.
+func (s *Schema) RatingScales() RatingScales {
+	scales := make(RatingScales)
+	for _, option := range s.options {
+		if option.Rating != nil {
+			scales[option.Name.Value] = *option.Rating
+		}
+	}
+	return scales
+}
+
+// schemaOption is the JSON form of a RequestOption in a marshalled Schema.
+// It keeps the encoding of RequestOption itself unchanged for existing consumers.
+type schemaOption struct {
+	Name        ReadableValue[string]            `json:"name"`
+	Control     RequestOptionType                `json:"control"`
+	Operators   []ReadableValue[CompareOperator] `json:"operators"`
+	Values      []string                         `json:"values,omitempty"`
+	MultiSelect bool                             `json:"multiSelect"`
+	Rating      *schemaRating                    `json:"rating,omitempty"`
+}
+
+// schemaRating is the JSON form of the rating scale of an option, the star values a frontend may offer.
+type schemaRating struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step"`
+}
+
+// MarshalJSON implements the json.Marshaler interface, exposing the schema as its list of options
+// with lower camel case keys: name, control, operators, values, multiSelect and, for options with a rating scale,
+// rating with its min, max and step.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	options := make([]schemaOption, len(s.options))
+	for i, option := range s.options {
+		options[i] = schemaOption{
+			Name:        option.Name,
+			Control:     option.Control,
+			Operators:   option.Operators,
+			Values:      option.Values,
+			MultiSelect: option.MultiSelect,
+		}
+		if option.Rating != nil {
+			options[i].Rating = &schemaRating{Min: option.Rating.Min, Max: option.Rating.Max, Step: option.Rating.Step}
+		}
+	}
+	return json.Marshal(options)
+}
+
+// Validate checks the request and all of its nested groups against the schema: every field must be declared,
+// use one of the operators of its option, hold values of its control type, only hold several values
+// for multi-select options, only hold declared values for enum options, and only hold star values of the option's
+// rating scale for the rating operators.
+// It returns an errs.InvalidRequestError naming the offending field.
+func (s *Schema) Validate(request *Request) error {
+	if request == nil {
+		return nil
+	}
+
+	if err := request.Validate(s.RatingScales()); err != nil {
+		return err
+	}
+
+	return s.validateGroup(request)
+}
+
+// validateGroup validates the fields of a request and of its nested groups against the schema.
+func (s *Schema) validateGroup(request *Request) error {
+	for _, field := range request.Fields {
+		if err := s.validateField(field); err != nil {
+			return errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: %w", field.Name, err))
+		}
+	}
+
+	for i := range request.Groups {
+		if err := s.validateGroup(&request.Groups[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateField validates a single field against its option.
+func (s *Schema) validateField(field RequestField) error {
+	option, ok := s.byName[field.Name]
+	if !ok {
+		return errors.New("field is not filterable")
+	}
+
+	if len(option.Operators) > 0 && !slices.ContainsFunc(option.Operators, func(operator ReadableValue[CompareOperator]) bool {
+		return operator.Value == field.Operator
+	}) {
+		return fmt.Errorf("operator %s is not allowed", field.Operator)
+	}
+
+	// exists only tells whether the field is set, its value is not of the field's type
+	if field.Operator == CompareOperatorExists {
+		return nil
+	}
+
+	values, isList := ValueList(field.Value)
+	if !isList {
+		values = []any{field.Value}
+	}
+	if isList && len(values) > 1 && !option.MultiSelect && field.Operator != CompareOperatorBetweenDates {
+		return errors.New("field does not accept multiple values")
+	}
+
+	for _, value := range values {
+		if _, err := coerceScalar(option.Control.Type, value); err != nil {
+			return err
+		}
+		if len(option.Values) > 0 {
+			str, _ := ValueString(value)
+			if !slices.Contains(option.Values, str) {
+				return fmt.Errorf("value %v is not one of %v", value, option.Values)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Coerce returns a copy of the request, including its nested groups, in which every field value has been converted
+// to the Go type of its option's control type (see Coerce), then validates the copy against the schema.
+// Translators such as the MongoDB and PostgreSQL query builders therefore receive typed values.
+// It returns an errs.InvalidRequestError naming the offending field.
+func (s *Schema) Coerce(request *Request) (*Request, error) {
+	if request == nil {
+		return nil, nil
+	}
+
+	coerced, err := s.coerceGroup(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Validate(coerced); err != nil {
+		return nil, err
+	}
+
+	return coerced, nil
+}
+
+// coerceGroup coerces the field values of a request and of its nested groups.
+func (s *Schema) coerceGroup(request *Request) (*Request, error) {
+	coerced := &Request{
+		Operator: request.Operator,
+		Fields:   make([]RequestField, len(request.Fields)),
+	}
+
+	for i, field := range request.Fields {
+		coerced.Fields[i] = field
+
+		option, ok := s.byName[field.Name]
+		if !ok {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: field is not filterable", field.Name))
+		}
+		// exists only tells whether the field is set, its value is not of the field's type
+		if field.Operator == CompareOperatorExists {
+			value, err := Coerce(ControlTypeBool, field.Value)
+			if err != nil {
+				return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: %w", field.Name, err))
+			}
+			coerced.Fields[i].Value = value
+			continue
+		}
+
+		value, err := Coerce(option.Control.Type, field.Value)
+		if err != nil {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: %w", field.Name, err))
+		}
+		coerced.Fields[i].Value = value
+	}
+
+	if len(request.Groups) > 0 {
+		coerced.Groups = make([]Request, len(request.Groups))
+		for i := range request.Groups {
+			group, err := s.coerceGroup(&request.Groups[i])
+			if err != nil {
+				return nil, err
+			}
+			coerced.Groups[i] = *group
+		}
+	}
+
+	return coerced, nil
+}
@@ -0,0 +1,111 @@
+package filter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var testStatusOption = RequestOption{
+	Name:        ReadableValue[string]{Label: "Status", Value: "status"},
+	Control:     RequestOptionType{Type: ControlTypeEnum},
+	Operators:   []ReadableValue[CompareOperator]{{Label: "Is", Value: CompareOperatorIsEqualTo}},
+	Values:      []string{"active", "archived"},
+	MultiSelect: true,
+}
+
+func TestSchemaMarshalJSON(t *testing.T) {
+	schema, err := NewSchema(testStatusOption)
+	if err != nil {
+		t.Fatalf("NewSchema() error = %v", err)
+	}
+
+	got, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `[{"name":{"label":"Status","value":"status"},"control":{"type":"enum"},"operators":[{"label":"Is","value":"isEqualTo"}],"values":["active","archived"],"multiSelect":true}]`
+	if string(got) != want {
+		t.Errorf("json.Marshal(schema) = %s, want %s", got, want)
+	}
+}
+
+func TestRequestOptionEncodingUnchanged(t *testing.T) {
+	got, err := json.Marshal(testStatusOption)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"Name":{"label":"Status","value":"status"},"Control":{"type":"enum"},"Operators":[{"label":"Is","value":"isEqualTo"}],"Values":["active","archived"],"MultiSelect":true}`
+	if string(got) != want {
+		t.Errorf("json.Marshal(option) = %s, want %s", got, want)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := NewSchema(testStatusOption)
+	if err != nil {
+		t.Fatalf("NewSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		field   RequestField
+		wantErr bool
+	}{
+		{name: "declared value", field: RequestField{Name: "status", Operator: CompareOperatorIsEqualTo, Value: "active"}},
+		{name: "several values", field: RequestField{Name: "status", Operator: CompareOperatorIsEqualTo, Value: []any{"active", "archived"}}},
+		{name: "undeclared value", field: RequestField{Name: "status", Operator: CompareOperatorIsEqualTo, Value: "deleted"}, wantErr: true},
+		{name: "operator not allowed", field: RequestField{Name: "status", Operator: CompareOperatorBeginsWith, Value: "act"}, wantErr: true},
+		{name: "field not declared", field: RequestField{Name: "password", Operator: CompareOperatorIsEqualTo, Value: "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(&Request{Operator: LogicOperatorAnd, Fields: []RequestField{tt.field}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaRatingScales(t *testing.T) {
+	scale := RatingScale{Min: 0, Max: 10, Step: 1, Rounding: RatingRoundDown}
+	schema, err := NewSchema(RequestOption{
+		Name:      ReadableValue[string]{Label: "Score", Value: "score"},
+		Control:   RequestOptionType{Type: ControlTypeInteger},
+		Operators: []ReadableValue[CompareOperator]{{Label: "At least", Value: CompareOperatorIsGreaterThanOrEqualToRating}},
+		Rating:    &scale,
+	}, testStatusOption)
+	if err != nil {
+		t.Fatalf("NewSchema() error = %v", err)
+	}
+
+	if got := schema.RatingScales(); len(got) != 1 || got["score"] != scale {
+		t.Errorf("RatingScales() = %+v, want the scale of score only", got)
+	}
+
+	request := &Request{Operator: LogicOperatorAnd, Fields: []RequestField{
+		{Name: "score", Operator: CompareOperatorIsGreaterThanOrEqualToRating, Value: 8},
+	}}
+	if err := schema.Validate(request); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	got, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `[{"name":{"label":"Score","value":"score"},"control":{"type":"integer"},"operators":[{"label":"At least","value":"isGreaterThanOrEqualToRating"}],"multiSelect":false,"rating":{"min":0,"max":10,"step":1}},` +
+		`{"name":{"label":"Status","value":"status"},"control":{"type":"enum"},"operators":[{"label":"Is","value":"isEqualTo"}],"values":["active","archived"],"multiSelect":true}]`
+	if string(got) != want {
+		t.Errorf("json.Marshal(schema) = %s, want %s", got, want)
+	}
+
+	if _, err := NewSchema(RequestOption{
+		Name:    ReadableValue[string]{Label: "Score", Value: "score"},
+		Control: RequestOptionType{Type: ControlTypeInteger},
+		Rating:  &RatingScale{Min: 0, Max: 10},
+	}); err == nil {
+		t.Errorf("NewSchema() accepted a rating scale without a step")
+	}
+}
@@ -0,0 +1,230 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+)
+
+// valueShape describes the form a field value must take for a given operator.
+type valueShape int
+
+const (
+	shapeString valueShape = iota
+	shapeNumber
+	shapeScalar
+	shapeScalarOrList
+	shapeDate
+	shapeDateRange
+	shapeOptionalBool
+	shapeIP
+	shapeRating
+)
+
+// operatorShapes maps every CompareOperator to the shape its value must have.
+var operatorShapes = map[CompareOperator]valueShape{
+	CompareOperatorBeginsWith:                     shapeString,
+	CompareOperatorDoesNotBeginWith:               shapeString,
+	CompareOperatorContains:                       shapeScalarOrList,
+	CompareOperatorDoesNotContain:                 shapeScalarOrList,
+	CompareOperatorTextContains:                   shapeString,
+	CompareOperatorIsNumberEqualTo:                shapeNumber,
+	CompareOperatorIsEqualTo:                      shapeScalarOrList,
+	CompareOperatorIsIpEqualTo:                    shapeIP,
+	CompareOperatorIsStringEqualTo:                shapeString,
+	CompareOperatorIsStringCaseInsensitiveEqualTo: shapeString,
+	CompareOperatorIsNotEqualTo:                   shapeScalarOrList,
+	CompareOperatorIsNumberNotEqualTo:             shapeNumber,
+	CompareOperatorIsIpNotEqualTo:                 shapeIP,
+	CompareOperatorIsStringNotEqualTo:             shapeString,
+	CompareOperatorIsGreaterThan:                  shapeScalar,
+	CompareOperatorIsGreaterThanOrEqualTo:         shapeScalar,
+	CompareOperatorIsLessThan:                     shapeScalar,
+	CompareOperatorIsLessThanOrEqualTo:            shapeScalar,
+	CompareOperatorBeforeDate:                     shapeDate,
+	CompareOperatorAfterDate:                      shapeDate,
+	CompareOperatorExists:                         shapeOptionalBool,
+	CompareOperatorIsEqualToRating:                shapeRating,
+	CompareOperatorIsNotEqualToRating:             shapeRating,
+	CompareOperatorIsGreaterThanRating:            shapeRating,
+	CompareOperatorIsLessThanRating:               shapeRating,
+	CompareOperatorIsGreaterThanOrEqualToRating:   shapeRating,
+	CompareOperatorIsLessThanOrEqualToRating:      shapeRating,
+	CompareOperatorBetweenDates:                   shapeDateRange,
+}
+
+// Validate checks that the request and all of its nested groups use a known logic operator and that every field is valid.
+// Rating values are checked against the scale of their field in scales, if given, or DefaultRatingScale.
+// It returns an errs.InvalidRequestError naming the offending field.
+func (r *Request) Validate(scales ...RatingScales) error {
+	if r == nil {
+		return nil
+	}
+	return r.validate(1, firstRatingScales(scales))
+}
+
+// validate validates the request found at the given group depth.
+func (r *Request) validate(depth int, scales RatingScales) error {
+	if depth > MaxGroupDepth {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("filter groups must not be nested deeper than %d levels", MaxGroupDepth))
+	}
+
+	if !r.Operator.IsValid() {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("filter operator %q: %w", r.Operator, ErrInvalidLogicOperator))
+	}
+
+	for _, field := range r.Fields {
+		if err := field.Validate(scales); err != nil {
+			return err
+		}
+	}
+
+	for i := range r.Groups {
+		if err := r.Groups[i].validate(depth+1, scales); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the field has a valid name, a known operator and a value shaped the way its operator expects.
+// Names must not start with $ or contain a NUL character, so that they cannot be read as query operators by the databases.
+// Rating values are checked against the scale of the field in scales, if given, or DefaultRatingScale.
+// It returns an errs.InvalidRequestError naming the offending field.
+func (f RequestField) Validate(scales ...RatingScales) error {
+	if f.Name == "" {
+		return errs.Body(errs.InvalidRequestError, errors.New("filter field name is required"))
+	}
+	if err := validateName(f.Name); err != nil {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: %w", f.Name, err))
+	}
+
+	if !f.Operator.IsValid() {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: %w", f.Name, ErrInvalidCompareOperator))
+	}
+
+	if err := validateShape(operatorShapes[f.Operator], f.Value, firstRatingScales(scales).Scale(f.Name)); err != nil {
+		return errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q: operator %s: %w", f.Name, f.Operator, err))
+	}
+
+	return nil
+}
+
+// validateShape reports whether value has the given shape. Rating values must be star values of scale.
+func validateShape(shape valueShape, value any, scale RatingScale) error {
+	switch shape {
+	case shapeString:
+		if _, ok := ValueString(value); !ok {
+			return fmt.Errorf("expected a string value, got %T", value)
+		}
+	case shapeNumber:
+		if _, ok := ValueNumber(value); !ok {
+			return fmt.Errorf("expected a number value, got %T", value)
+		}
+	case shapeScalar:
+		if value == nil {
+			return errors.New("value is required")
+		}
+		if _, ok := ValueList(value); ok {
+			return errors.New("expected a single value, not a list")
+		}
+		if err := validateScalar(value); err != nil {
+			return err
+		}
+	case shapeScalarOrList:
+		if value == nil {
+			return errors.New("value is required")
+		}
+		list, ok := ValueList(value)
+		if !ok {
+			return validateScalar(value)
+		}
+		if len(list) == 0 {
+			return errors.New("value list must not be empty")
+		}
+		for _, element := range list {
+			if err := validateScalar(element); err != nil {
+				return err
+			}
+		}
+	case shapeDate:
+		if _, err := ValueTime(value); err != nil {
+			return err
+		}
+	case shapeDateRange:
+		if _, _, err := ValueTimeRange(value); err != nil {
+			return err
+		}
+	case shapeRating:
+		stars, ok := ValueNumber(value)
+		if !ok {
+			return fmt.Errorf("expected a number value, got %T", value)
+		}
+		if err := scale.Validate(stars); err != nil {
+			return err
+		}
+	case shapeIP:
+		if _, err := ValueIPPrefix(value); err != nil {
+			return err
+		}
+	case shapeOptionalBool:
+		if value == nil {
+			return nil
+		}
+		if _, ok := ValueBool(value); !ok {
+			return fmt.Errorf("expected a boolean value, got %T", value)
+		}
+	}
+
+	return nil
+}
+
+// validateName reports whether name can be used as a field name.
+// Every dot-separated part of the name is checked, as databases read parts starting with $ as operators.
+func validateName(name string) error {
+	if strings.ContainsRune(name, 0) {
+		return errors.New("field name must not contain a NUL character")
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return errors.New("field name must not have empty parts")
+		}
+		if strings.HasPrefix(part, "$") {
+			return errors.New("field name must not start with $")
+		}
+	}
+	return nil
+}
+
+// validateScalar reports whether value is a single value that can be compared to a field.
+// Maps and structs are rejected, dates excepted: a database would read them as documents of query operators,
+// such as {"$ne": null}, rather than as values.
+func validateScalar(value any) error {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		return fmt.Errorf("expected a single value, got an object (%T)", value)
+	case reflect.Struct:
+		if rv.Type() == reflect.TypeFor[time.Time]() {
+			return nil
+		}
+		return fmt.Errorf("expected a single value, got an object (%T)", value)
+	case reflect.Slice, reflect.Array:
+		if _, ok := ValueList(rv.Interface()); ok {
+			return errors.New("expected a single value, not a list")
+		}
+	}
+	return nil
+}
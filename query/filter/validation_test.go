@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/leetatech/leeta_golang_libraries/errs"
+)
+
+func TestRequestFieldValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   RequestField
+		wantErr bool
+	}{
+		{name: "string", field: RequestField{Name: "name", Operator: CompareOperatorIsEqualTo, Value: "john"}},
+		{name: "list", field: RequestField{Name: "name", Operator: CompareOperatorIsEqualTo, Value: []any{"john", 1}}},
+		{name: "date", field: RequestField{Name: "createdAt", Operator: CompareOperatorIsGreaterThan, Value: time.Now()}},
+		{name: "uuid", field: RequestField{Name: "id", Operator: CompareOperatorIsEqualTo, Value: uuid.New()}},
+		{name: "nested name", field: RequestField{Name: "address.city", Operator: CompareOperatorIsEqualTo, Value: "Lagos"}},
+		{name: "operator object", field: RequestField{Name: "password", Operator: CompareOperatorIsEqualTo, Value: map[string]any{"$ne": nil}}, wantErr: true},
+		{name: "operator object in list", field: RequestField{Name: "password", Operator: CompareOperatorContains, Value: []any{"a", map[string]any{"$ne": nil}}}, wantErr: true},
+		{name: "struct", field: RequestField{Name: "age", Operator: CompareOperatorIsGreaterThan, Value: struct{ Gt int }{Gt: 1}}, wantErr: true},
+		{name: "pointer to map", field: RequestField{Name: "age", Operator: CompareOperatorIsNotEqualTo, Value: &map[string]any{"$gt": 1}}, wantErr: true},
+		{name: "list of lists", field: RequestField{Name: "age", Operator: CompareOperatorIsEqualTo, Value: []any{[]any{1}}}, wantErr: true},
+		{name: "empty name", field: RequestField{Name: "", Operator: CompareOperatorIsEqualTo, Value: "x"}, wantErr: true},
+		{name: "operator name", field: RequestField{Name: "$where", Operator: CompareOperatorIsEqualTo, Value: "1"}, wantErr: true},
+		{name: "nested operator name", field: RequestField{Name: "profile.$where", Operator: CompareOperatorIsEqualTo, Value: "1"}, wantErr: true},
+		{name: "empty name part", field: RequestField{Name: "profile.", Operator: CompareOperatorIsEqualTo, Value: "1"}, wantErr: true},
+		{name: "NUL in name", field: RequestField{Name: "name\x00", Operator: CompareOperatorIsEqualTo, Value: "1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.Validate()
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var response *errs.Response
+			if !errors.As(err, &response) || response.ErrorCode != errs.InvalidRequestError {
+				t.Fatalf("Validate() = %v, want an InvalidRequestError", err)
+			}
+			if tt.field.Name != "" && !strings.Contains(response.Message, strconv.Quote(tt.field.Name)) {
+				t.Errorf("Validate() = %q, want the field name in the message", response.Message)
+			}
+		})
+	}
+}
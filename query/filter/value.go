@@ -0,0 +1,260 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts lists the layouts accepted when a date value is given as a string.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+}
+
+// ValueList returns the elements of value if it is a slice or an array.
+// The second return value reports whether value is a list at all.
+func ValueList(value any) ([]any, bool) {
+	if value == nil {
+		return nil, false
+	}
+
+	if list, ok := value.([]any); ok {
+		return list, true
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	// []byte is a scalar for filtering purposes
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, true
+}
+
+// ValueString returns value as a string if it is one.
+func ValueString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// ValueNumber returns value as a float64 if it holds any of the Go numeric types.
+func ValueNumber(value any) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// ValueTime returns value as a time.Time. Strings are parsed as RFC 3339 timestamps or plain dates (2006-01-02).
+func ValueTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, errors.New("date value is nil")
+		}
+		return *v, nil
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%q is not a valid date, expected RFC 3339 or 2006-01-02", v)
+	default:
+		return time.Time{}, fmt.Errorf("%v (%T) is not a valid date", value, value)
+	}
+}
+
+// ValueTimeRange returns the two bounds of a date range given as a two-element list.
+func ValueTimeRange(value any) (from, to time.Time, err error) {
+	list, ok := ValueList(value)
+	if !ok || len(list) != 2 {
+		return from, to, errors.New("date range must be a list of exactly two dates")
+	}
+
+	if from, err = ValueTime(list[0]); err != nil {
+		return from, to, err
+	}
+	if to, err = ValueTime(list[1]); err != nil {
+		return from, to, err
+	}
+	if to.Before(from) {
+		return from, to, errors.New("date range end is before its start")
+	}
+
+	return from, to, nil
+}
+
+// ValueIP returns value as a normalized IP address: IPv4-mapped IPv6 addresses are unmapped to IPv4 and zones are dropped,
+// so that the same address always compares equal. value may be a netip.Addr, its string form,
+// or its 4 or 16 bytes, the form IP addresses are stored in by the MongoDB query builder.
+func ValueIP(value any) (netip.Addr, error) {
+	var addr netip.Addr
+	switch v := value.(type) {
+	case netip.Addr:
+		addr = v
+	case []byte:
+		var ok bool
+		if addr, ok = netip.AddrFromSlice(v); !ok {
+			return netip.Addr{}, fmt.Errorf("%d bytes are not a valid IP address", len(v))
+		}
+	default:
+		s, ok := ValueString(value)
+		if !ok {
+			return netip.Addr{}, fmt.Errorf("expected an IP address, got %T", value)
+		}
+		var err error
+		if addr, err = netip.ParseAddr(s); err != nil {
+			return netip.Addr{}, fmt.Errorf("%q is not a valid IP address", s)
+		}
+	}
+	if !addr.IsValid() {
+		return netip.Addr{}, errors.New("IP address is not set")
+	}
+
+	return addr.Unmap().WithZone(""), nil
+}
+
+// ValueIPPrefix returns the IP range selected by an IP filter value: either a CIDR block such as 10.0.0.0/8,
+// or a single address, returned as a prefix holding only that address. The prefix is masked and normalized like ValueIP,
+// so ::ffff:10.0.0.0/104 selects the same addresses as 10.0.0.0/8.
+// value may be a netip.Prefix, the string form of a CIDR block, or any address accepted by ValueIP.
+func ValueIPPrefix(value any) (netip.Prefix, error) {
+	prefix, ok := value.(netip.Prefix)
+	if !ok {
+		s, isString := ValueString(value)
+		if !isString || !strings.Contains(s, "/") {
+			addr, err := ValueIP(value)
+			if err != nil {
+				return netip.Prefix{}, err
+			}
+			return netip.PrefixFrom(addr, addr.BitLen()), nil
+		}
+
+		var err error
+		if prefix, err = netip.ParsePrefix(s); err != nil {
+			return netip.Prefix{}, fmt.Errorf("%q is not a valid CIDR block", s)
+		}
+	}
+	if !prefix.IsValid() {
+		return netip.Prefix{}, errors.New("CIDR block is not set")
+	}
+
+	addr, bits := prefix.Addr(), prefix.Bits()
+	if addr.Is4In6() && bits >= 96 {
+		addr, bits = addr.Unmap(), bits-96
+	}
+	return netip.PrefixFrom(addr, bits).Masked(), nil
+}
+
+// ValueBool returns value as a bool if it is one.
+func ValueBool(value any) (bool, bool) {
+	b, ok := value.(bool)
+	return b, ok
+}
+
+// ValueFromStrings builds the value of a field using operator from its textual form, as found in a query string.
+// Numbers and booleans are parsed for the operators expecting them, and several strings form a list.
+// Values of other operators are kept as strings; Schema.Coerce converts them to the type of their field.
+func ValueFromStrings(operator CompareOperator, values []string) (any, error) {
+	parsed := make([]any, len(values))
+	for i, value := range values {
+		switch operatorShapes[operator] {
+		case shapeNumber, shapeRating:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid number", value)
+			}
+			parsed[i] = n
+		case shapeOptionalBool:
+			if value == "" {
+				parsed[i] = nil
+				continue
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid boolean", value)
+			}
+			parsed[i] = b
+		default:
+			parsed[i] = value
+		}
+	}
+
+	if len(parsed) == 1 && operatorShapes[operator] != shapeDateRange {
+		return parsed[0], nil
+	}
+	return parsed, nil
+}
+
+// ValueStrings returns the textual form of a value, one string per element for lists, as used in a query string.
+func ValueStrings(value any) []string {
+	list, ok := ValueList(value)
+	if !ok {
+		list = []any{value}
+	}
+
+	strs := make([]string, len(list))
+	for i, element := range list {
+		strs[i] = valueString(element)
+	}
+	return strs
+}
+
+// valueString returns the textual form of a single value.
+func valueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	if s, ok := ValueString(value); ok {
+		return s
+	}
+	if rv := reflect.ValueOf(value); rv.CanInt() {
+		return strconv.FormatInt(rv.Int(), 10)
+	} else if rv.CanUint() {
+		return strconv.FormatUint(rv.Uint(), 10)
+	}
+	if n, ok := ValueNumber(value); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
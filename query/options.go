@@ -0,0 +1,36 @@
+package query
+
+import (
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+)
+
+// Options configures how a ResultSelector is applied, by SelectSlice and by the database query builders.
+// The zero value applies the package defaults.
+//   - Limits: The limits page requests are validated and capped against. paging.DefaultLimits when nil.
+//   - RatingScales: The rating scales of the filter fields, e.g. from filter.Schema.RatingScales.
+//     Fields without a scale use filter.DefaultRatingScale.
+//   - BinaryIPFields: The filter fields whose IP addresses are stored in the binary form of mongodb.IPValue
+//     rather than as strings. Only used by the MongoDB query builders.
+type Options struct {
+	Limits         *paging.Limits
+	RatingScales   filter.RatingScales
+	BinaryIPFields []string
+}
+
+// PagingLimits returns the limits page requests are validated and capped against.
+func (o Options) PagingLimits() paging.Limits {
+	if o.Limits == nil {
+		return paging.DefaultLimits
+	}
+	return *o.Limits
+}
+
+// FirstOptions returns the first of options, or the zero Options if there are none.
+// It lets functions take their options as an optional trailing argument.
+func FirstOptions(options []Options) Options {
+	if len(options) == 0 {
+		return Options{}
+	}
+	return options[0]
+}
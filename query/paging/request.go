@@ -1,6 +1,37 @@
 package paging
 
+import "math"
+
+// DefaultPageSize is the number of records per page used when a request does not specify a page size.
+const DefaultPageSize = 10
+
+// Request represents a paging request.
+//   - PageIndex: The index of the page (starting from 0). Ignored in cursor mode.
+//   - PageSize: The number of records per page. DefaultPageSize is used when it is less than 1.
+//   - Cursor: An opaque cursor taken from the `next` or `previous` field of a previous Response.
+//     When set, the page is located relative to the record the cursor was built from instead of by index.
 type Request struct {
-	PageIndex int `json:"index"`
-	PageSize  int `json:"size"`
+	PageIndex int    `json:"index"`
+	PageSize  int    `json:"size"`
+	Cursor    string `json:"cursor,omitempty"`
+}
+
+// Limit returns the number of records to return for the requested page.
+func (r *Request) Limit() int {
+	if r.PageSize < 1 {
+		return DefaultPageSize
+	}
+	return r.PageSize
+}
+
+// Offset returns the number of records to skip to reach the requested page.
+// Offsets that do not fit in an int are returned as math.MaxInt; Validate rejects the page indexes leading to them.
+func (r *Request) Offset() int {
+	if r.PageIndex < 0 {
+		return 0
+	}
+	if r.PageIndex > math.MaxInt/r.Limit() {
+		return math.MaxInt
+	}
+	return r.PageIndex * r.Limit()
 }
@@ -1,24 +1,64 @@
 package paging
 
+// DefaultMaxDisplayableResults is the number of results that can be reached through page index pagination.
+// Skipping over more records gets too slow on large collections, cursor pagination should be used beyond it.
+const DefaultMaxDisplayableResults uint64 = 10000
+
 // Response represents a response object containing information about pagination and total count of records.
 //   - PageIndex: The index of the page (starting from 0). This is required.
 //   - PageSize: The number of records per page. This is required.
 //   - TotalDisplayableResults: The total number of results that can be paginated. Due to database restrictions, in case of large number of results, some of the results cannot be retrieved. In such cases, this number will be lower than the `TotalResults`. This is required.
 //   - TotalResults: The total count of results as it exists in database, including those that may not be retrieved. This is optional and must not be set if the value does not differ from `TotalDisplayableResults`
+//   - TotalPages: The number of pages needed to display `TotalDisplayableResults`.
+//   - HasNext: Indicates whether a page follows this one.
+//   - HasPrevious: Indicates whether a page precedes this one.
+//   - NextCursor: The cursor to request the page following this one, in cursor mode. Empty on the last page.
+//   - PreviousCursor: The cursor to request the page preceding this one, in cursor mode. Empty on the first page.
 type Response struct {
-	PageIndex    int    `json:"index" binding:"required"`
-	PageSize     int    `json:"size" binding:"required"`
-	TotalResults uint64 `json:"totalResults,omitempty"`
+	PageIndex               int    `json:"index" binding:"required"`
+	PageSize                int    `json:"size" binding:"required"`
+	TotalDisplayableResults uint64 `json:"totalDisplayableResults"`
+	TotalResults            uint64 `json:"totalResults,omitempty"`
+	TotalPages              uint64 `json:"totalPages"`
+	HasNext                 bool   `json:"hasNext"`
+	HasPrevious             bool   `json:"hasPrevious"`
+	NextCursor              string `json:"next,omitempty"`
+	PreviousCursor          string `json:"previous,omitempty"`
 }
 
+// NewResponse creates the paging response for request given the total number of matching records,
+// capping the displayable results at DefaultMaxDisplayableResults.
 func NewResponse(request *Request, totalResults uint64) *Response {
+	return NewCappedResponse(request, totalResults, DefaultMaxDisplayableResults)
+}
+
+// NewCappedResponse creates the paging response for request given the total number of matching records,
+// capping the displayable results at maxDisplayableResults. A maxDisplayableResults of 0 disables the cap.
+// TotalResults is only set when the cap was reached, as it would otherwise repeat TotalDisplayableResults.
+func NewCappedResponse(request *Request, totalResults, maxDisplayableResults uint64) *Response {
 	if request == nil {
 		return nil
 	}
 
-	return &Response{
-		PageIndex:    request.PageIndex,
-		PageSize:     request.PageSize,
-		TotalResults: totalResults,
+	displayable := totalResults
+	if maxDisplayableResults > 0 && displayable > maxDisplayableResults {
+		displayable = maxDisplayableResults
+	}
+
+	pageSize := uint64(request.Limit())
+	totalPages := (displayable + pageSize - 1) / pageSize
+	pageIndex := uint64(max(request.PageIndex, 0))
+
+	response := &Response{
+		PageIndex:               request.PageIndex,
+		PageSize:                request.PageSize,
+		TotalDisplayableResults: displayable,
+		TotalPages:              totalPages,
+		HasNext:                 pageIndex+1 < totalPages,
+		HasPrevious:             pageIndex > 0,
+	}
+	if displayable != totalResults {
+		response.TotalResults = totalResults
 	}
+	return response
 }
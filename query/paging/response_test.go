@@ -0,0 +1,79 @@
+package paging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewCappedResponse(t *testing.T) {
+	tests := []struct {
+		name                  string
+		request               *Request
+		totalResults          uint64
+		maxDisplayableResults uint64
+		want                  *Response
+	}{
+		{
+			name:    "nil request",
+			request: nil,
+			want:    nil,
+		},
+		{
+			name:                  "below the cap",
+			request:               &Request{PageIndex: 1, PageSize: 10},
+			totalResults:          25,
+			maxDisplayableResults: 100,
+			want: &Response{
+				PageIndex:               1,
+				PageSize:                10,
+				TotalDisplayableResults: 25,
+				TotalPages:              3,
+				HasNext:                 true,
+				HasPrevious:             true,
+			},
+		},
+		{
+			name:                  "capped",
+			request:               &Request{PageIndex: 0, PageSize: 10},
+			totalResults:          250,
+			maxDisplayableResults: 100,
+			want: &Response{
+				PageIndex:               0,
+				PageSize:                10,
+				TotalDisplayableResults: 100,
+				TotalResults:            250,
+				TotalPages:              10,
+				HasNext:                 true,
+			},
+		},
+		{
+			name:                  "no cap",
+			request:               &Request{PageIndex: 2, PageSize: 10},
+			totalResults:          25,
+			maxDisplayableResults: 0,
+			want: &Response{
+				PageIndex:               2,
+				PageSize:                10,
+				TotalDisplayableResults: 25,
+				TotalPages:              3,
+				HasPrevious:             true,
+			},
+		},
+		{
+			name:                  "no results",
+			request:               &Request{},
+			totalResults:          0,
+			maxDisplayableResults: 100,
+			want:                  &Response{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCappedResponse(tt.request, tt.totalResults, tt.maxDisplayableResults)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewCappedResponse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package paging
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+)
+
+// Limits configures the page requests accepted by Request.Validate.
+//   - MaxPageSize: The largest number of records a single page may hold.
+//   - MaxDisplayableResults: The number of results reachable through page index pagination, 0 disables the check.
+type Limits struct {
+	MaxPageSize           int
+	MaxDisplayableResults uint64
+}
+
+// DefaultLimits are the limits used by Request.Validate when none are given.
+// Services may override them once at start-up.
+var DefaultLimits = Limits{
+	MaxPageSize:           100,
+	MaxDisplayableResults: DefaultMaxDisplayableResults,
+}
+
+// Validate checks the request against the given limits, or DefaultLimits if none are given.
+// Page indexes start from 0 and a page size of 0 selects DefaultPageSize.
+// It returns an errs.InvalidPageRequestError describing the first violation.
+func (r *Request) Validate(limits ...Limits) error {
+	if r == nil {
+		return nil
+	}
+
+	conf := DefaultLimits
+	if len(limits) > 0 {
+		conf = limits[0]
+	}
+
+	if r.PageIndex < 0 {
+		return errs.Body(errs.InvalidPageRequestError, fmt.Errorf("page index %d must not be negative, the first page is 0", r.PageIndex))
+	}
+
+	if r.PageSize < 0 {
+		return errs.Body(errs.InvalidPageRequestError, fmt.Errorf("page size %d must not be negative", r.PageSize))
+	}
+
+	if conf.MaxPageSize > 0 && r.PageSize > conf.MaxPageSize {
+		return errs.Body(errs.InvalidPageRequestError, fmt.Errorf("page size %d exceeds the maximum of %d", r.PageSize, conf.MaxPageSize))
+	}
+
+	if r.PageIndex > math.MaxInt/r.Limit() {
+		return errs.Body(errs.InvalidPageRequestError, fmt.Errorf("page index %d is too large for pages of %d records", r.PageIndex, r.Limit()))
+	}
+
+	if r.Cursor == "" && conf.MaxDisplayableResults > 0 && uint64(r.Offset()) >= conf.MaxDisplayableResults {
+		return errs.Body(errs.InvalidPageRequestError, fmt.Errorf("page index %d is beyond the first %d results, use cursor pagination instead", r.PageIndex, conf.MaxDisplayableResults))
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package paging
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+)
+
+func TestRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *Request
+		limits  []Limits
+		wantErr bool
+	}{
+		{name: "nil request", request: nil},
+		{name: "first page", request: &Request{PageIndex: 0, PageSize: 10}},
+		{name: "default page size", request: &Request{PageIndex: 3}},
+		{name: "last displayable page", request: &Request{PageIndex: 99, PageSize: 100}},
+		{name: "beyond the displayable results", request: &Request{PageIndex: 100, PageSize: 100}, wantErr: true},
+		{name: "cursor beyond the displayable results", request: &Request{PageIndex: 100, PageSize: 100, Cursor: "c"}},
+		{name: "negative page index", request: &Request{PageIndex: -1}, wantErr: true},
+		{name: "negative page size", request: &Request{PageSize: -1}, wantErr: true},
+		{name: "page size over the maximum", request: &Request{PageSize: 101}, wantErr: true},
+		{name: "page size within custom limits", request: &Request{PageSize: 500}, limits: []Limits{{MaxPageSize: 500}}},
+		{name: "overflowing offset", request: &Request{PageIndex: 1844674407370955162, PageSize: 10}, limits: []Limits{{MaxPageSize: 100}}, wantErr: true},
+		{name: "overflowing offset in cursor mode", request: &Request{PageIndex: math.MaxInt, PageSize: 2, Cursor: "c"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate(tt.limits...)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			var response *errs.Response
+			if !errors.As(err, &response) || response.ErrorCode != errs.InvalidPageRequestError {
+				t.Errorf("Validate() error = %v, want an InvalidPageRequestError", err)
+			}
+		})
+	}
+}
+
+func TestRequestOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		request Request
+		want    int
+	}{
+		{name: "first page", request: Request{PageIndex: 0, PageSize: 10}, want: 0},
+		{name: "third page", request: Request{PageIndex: 2, PageSize: 25}, want: 50},
+		{name: "default page size", request: Request{PageIndex: 2}, want: 2 * DefaultPageSize},
+		{name: "negative page index", request: Request{PageIndex: -1, PageSize: 10}, want: 0},
+		{name: "overflowing offset", request: Request{PageIndex: 1844674407370955162, PageSize: 10}, want: math.MaxInt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.request.Offset(); got != tt.want {
+				t.Errorf("Offset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+// Query string parameter names used to encode a ResultSelector.
+const (
+	filterOperatorParam = "filter[operator]"
+	sortParam           = "sort"
+	pageParam           = "page"
+	sizeParam           = "size"
+	cursorParam         = "cursor"
+)
+
+// filterFieldParam matches the filter[<field>][<operator>] parameters.
+var filterFieldParam = regexp.MustCompile(`^filter\[([^\[\]]+)\]\[([^\[\]]+)\]$`)
+
+// ParseQueryString parses a raw URL query into a ResultSelector. See ParseValues for the expected format.
+func ParseQueryString(rawQuery string, options ...Options) (ResultSelector, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ResultSelector{}, errs.Body(errs.InvalidRequestError, fmt.Errorf("invalid query string: %w", err))
+	}
+	return ParseValues(values, options...)
+}
+
+// ParseValues parses URL query values such as
+//
+//	?filter[status][isEqualTo]=active&filter[city][contains]=Lagos&filter[city][contains]=Abuja&sort=-price,name&page=2&size=20
+//
+// into a ResultSelector:
+//   - filter[<field>][<operator>]=<value> adds a filter field; repeating the parameter gives a list of values.
+//     Values are strings, except for the operators expecting numbers or booleans; Schema.Coerce types them per field.
+//   - filter[operator]=and|or sets the logic operator of the filter, "and" by default.
+//   - sort=<column>,-<column> sets the sort keys in order, a leading "-" sorting in descending order.
+//   - page=<index>, size=<size> and cursor=<cursor> set the paging request.
+//
+// Other parameters are ignored. Parts without any parameter are left nil.
+// The filter is validated with the rating scales of options, and the paging request against the limits of options,
+// if given, or paging.DefaultLimits.
+// An errs.InvalidRequestError is returned for malformed parameters and invalid filter or paging requests.
+func ParseValues(values url.Values, options ...Options) (ResultSelector, error) {
+	var resultSelector ResultSelector
+
+	requestFilter, err := parseFilter(values)
+	if err != nil {
+		return resultSelector, err
+	}
+	resultSelector.Filter = requestFilter
+
+	resultSelector.Sorting = parseSorting(values.Get(sortParam))
+
+	resultSelector.Paging, err = parsePaging(values)
+	if err != nil {
+		return resultSelector, err
+	}
+
+	opts := FirstOptions(options)
+	if err := resultSelector.Filter.Validate(opts.RatingScales); err != nil {
+		return resultSelector, err
+	}
+	if err := resultSelector.Paging.Validate(opts.PagingLimits()); err != nil {
+		// report it like the other query string errors, keeping the message of the paging error
+		var response *errs.Response
+		if errors.As(err, &response) {
+			err = errors.New(response.Message)
+		}
+		return resultSelector, errs.Body(errs.InvalidRequestError, err)
+	}
+
+	return resultSelector, nil
+}
+
+// parseFilter builds the filter request from the filter parameters, sorted by field and operator.
+func parseFilter(values url.Values) (*filter.Request, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if filterFieldParam.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	operator := values.Get(filterOperatorParam)
+	if len(keys) == 0 && operator == "" {
+		return nil, nil
+	}
+	slices.Sort(keys)
+
+	requestFilter := &filter.Request{Operator: filter.LogicOperatorAnd}
+	if operator != "" {
+		logicOperator, err := filter.ParseLogicOperator(operator)
+		if err != nil {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("%s: %w", filterOperatorParam, err))
+		}
+		requestFilter.Operator = logicOperator
+	}
+
+	for _, key := range keys {
+		match := filterFieldParam.FindStringSubmatch(key)
+
+		compareOperator, err := filter.ParseCompareOperator(match[2])
+		if err != nil {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("%s: %w", key, err))
+		}
+
+		value, err := filter.ValueFromStrings(compareOperator, values[key])
+		if err != nil {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("%s: %w", key, err))
+		}
+
+		requestFilter.Fields = append(requestFilter.Fields, filter.RequestField{
+			Name:     match[1],
+			Operator: compareOperator,
+			Value:    value,
+		})
+	}
+
+	return requestFilter, nil
+}
+
+// parseSorting builds the sorting request from a comma separated list of columns.
+func parseSorting(sort string) *sorting.Request {
+	if sort == "" {
+		return nil
+	}
+
+	request := &sorting.Request{}
+	for _, column := range strings.Split(sort, ",") {
+		column = strings.TrimSpace(column)
+		direction := sorting.DirectionAscending
+		if strings.HasPrefix(column, "-") {
+			column, direction = column[1:], sorting.DirectionDescending
+		} else {
+			column = strings.TrimPrefix(column, "+")
+		}
+		if column == "" {
+			continue
+		}
+		request.Keys = append(request.Keys, sorting.Key{Column: column, Direction: direction})
+	}
+
+	if len(request.Keys) == 0 {
+		return nil
+	}
+	return request
+}
+
+// parsePaging builds the paging request from the page, size and cursor parameters.
+func parsePaging(values url.Values) (*paging.Request, error) {
+	page, size, cursor := values.Get(pageParam), values.Get(sizeParam), values.Get(cursorParam)
+	if page == "" && size == "" && cursor == "" {
+		return nil, nil
+	}
+
+	request := &paging.Request{Cursor: cursor}
+	var err error
+	if page != "" {
+		if request.PageIndex, err = strconv.Atoi(page); err != nil {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("%s: %q is not a valid page index", pageParam, page))
+		}
+	}
+	if size != "" {
+		if request.PageSize, err = strconv.Atoi(size); err != nil {
+			return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("%s: %q is not a valid page size", sizeParam, size))
+		}
+	}
+
+	return request, nil
+}
+
+// Values encodes the ResultSelector as URL query values in the format read by ParseValues.
+// An errs.InvalidRequestError is returned for selectors that cannot be expressed in a query string:
+// filters with nested groups, with field names that are empty or hold square brackets,
+// or holding the same field and operator twice.
+func (r ResultSelector) Values() (url.Values, error) {
+	values := url.Values{}
+
+	if r.Filter != nil {
+		if len(r.Filter.Groups) > 0 {
+			return nil, errs.Body(errs.InvalidRequestError, errors.New("nested filter groups cannot be encoded in a query string"))
+		}
+		if r.Filter.Operator != "" && r.Filter.Operator != filter.LogicOperatorAnd {
+			values.Set(filterOperatorParam, r.Filter.Operator.String())
+		}
+		for _, field := range r.Filter.Fields {
+			if field.Name == "" || strings.ContainsAny(field.Name, "[]") {
+				return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field name %q cannot be encoded in a query string", field.Name))
+			}
+			key := fmt.Sprintf("filter[%s][%s]", field.Name, field.Operator)
+			if values.Has(key) {
+				return nil, errs.Body(errs.InvalidRequestError, fmt.Errorf("filter field %q uses %s twice", field.Name, field.Operator))
+			}
+			values[key] = filter.ValueStrings(field.Value)
+		}
+	}
+
+	if keys := r.Sorting.SortKeys(); len(keys) > 0 {
+		columns := make([]string, len(keys))
+		for i, key := range keys {
+			columns[i] = key.Column
+			if sorting.SortDirectionFromString(string(key.Direction)) == sorting.DirectionDescending {
+				columns[i] = "-" + key.Column
+			}
+		}
+		values.Set(sortParam, strings.Join(columns, ","))
+	}
+
+	if r.Paging != nil {
+		values.Set(pageParam, strconv.Itoa(r.Paging.PageIndex))
+		values.Set(sizeParam, strconv.Itoa(r.Paging.PageSize))
+		if r.Paging.Cursor != "" {
+			values.Set(cursorParam, r.Paging.Cursor)
+		}
+	}
+
+	return values, nil
+}
+
+// QueryString encodes the ResultSelector as a raw URL query in the format read by ParseQueryString.
+func (r ResultSelector) QueryString() (string, error) {
+	values, err := r.Values()
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
@@ -0,0 +1,158 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+func TestQueryStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector ResultSelector
+	}{
+		{
+			name:     "empty",
+			selector: ResultSelector{},
+		},
+		{
+			name: "filter, sorting and paging",
+			selector: ResultSelector{
+				Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+					{Name: "city", Operator: filter.CompareOperatorContains, Value: []any{"Lagos", "Abuja"}},
+					{Name: "createdAt", Operator: filter.CompareOperatorBetweenDates, Value: []any{"2024-01-01", "2024-12-31"}},
+					{Name: "deletedAt", Operator: filter.CompareOperatorExists, Value: false},
+					{Name: "price", Operator: filter.CompareOperatorIsNumberEqualTo, Value: 12.5},
+					{Name: "status", Operator: filter.CompareOperatorIsEqualTo, Value: "active"},
+				}},
+				Sorting: &sorting.Request{Keys: []sorting.Key{
+					{Column: "price", Direction: sorting.DirectionDescending},
+					{Column: "name", Direction: sorting.DirectionAscending},
+				}},
+				Paging: &paging.Request{PageIndex: 2, PageSize: 20},
+			},
+		},
+		{
+			name: "or filter and cursor",
+			selector: ResultSelector{
+				Filter: &filter.Request{Operator: filter.LogicOperatorOr, Fields: []filter.RequestField{
+					{Name: "name", Operator: filter.CompareOperatorBeginsWith, Value: "a&b=c"},
+					{Name: "name", Operator: filter.CompareOperatorTextContains, Value: "red shoes"},
+				}},
+				Paging: &paging.Request{PageSize: 10, Cursor: "abc.def"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawQuery, err := tt.selector.QueryString()
+			if err != nil {
+				t.Fatalf("QueryString() error = %v", err)
+			}
+
+			got, err := ParseQueryString(rawQuery)
+			if err != nil {
+				t.Fatalf("ParseQueryString(%q) error = %v", rawQuery, err)
+			}
+			if !reflect.DeepEqual(got, tt.selector) {
+				t.Errorf("ParseQueryString(%q) = %+v, want %+v", rawQuery, got, tt.selector)
+			}
+		})
+	}
+}
+
+func TestParseQueryStringRejectsInvalidSelectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawQuery string
+	}{
+		{name: "unknown operator", rawQuery: "filter[name][isLike]=a"},
+		{name: "unknown logic operator", rawQuery: "filter[operator]=xor&filter[name][isEqualTo]=a"},
+		{name: "invalid date range", rawQuery: "filter[d][betweenDates]=x"},
+		{name: "duplicate single value operator", rawQuery: "filter[price][isGreaterThan]=1&filter[price][isGreaterThan]=2"},
+		{name: "not a number", rawQuery: "filter[price][isNumberEqualTo]=abc"},
+		{name: "operator field name", rawQuery: "filter[$where][isEqualTo]=1"},
+		{name: "negative page", rawQuery: "page=-1"},
+		{name: "page size over the limit", rawQuery: "size=100000"},
+		{name: "malformed page", rawQuery: "page=one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQueryString(tt.rawQuery)
+
+			var response *errs.Response
+			if !errors.As(err, &response) || response.ErrorCode != errs.InvalidRequestError {
+				t.Errorf("ParseQueryString(%q) error = %v, want an InvalidRequestError", tt.rawQuery, err)
+			}
+		})
+	}
+}
+
+func TestParseQueryStringLimits(t *testing.T) {
+	options := Options{Limits: &paging.Limits{MaxPageSize: 500}}
+
+	got, err := ParseQueryString("page=1&size=250", options)
+	if err != nil {
+		t.Fatalf("ParseQueryString() error = %v", err)
+	}
+	if want := (&paging.Request{PageIndex: 1, PageSize: 250}); !reflect.DeepEqual(got.Paging, want) {
+		t.Errorf("ParseQueryString() paging = %+v, want %+v", got.Paging, want)
+	}
+}
+
+func TestQueryStringRejectsUnencodableSelectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *filter.Request
+	}{
+		{
+			name: "nested groups",
+			filter: &filter.Request{Operator: filter.LogicOperatorOr, Groups: []filter.Request{
+				{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{{Name: "a", Operator: filter.CompareOperatorIsEqualTo, Value: "1"}}},
+			}},
+		},
+		{
+			name: "same field and operator twice",
+			filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 1},
+				{Name: "price", Operator: filter.CompareOperatorIsGreaterThan, Value: 2},
+			}},
+		},
+		{
+			name: "empty field name",
+			filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "", Operator: filter.CompareOperatorIsEqualTo, Value: "a"},
+			}},
+		},
+		{
+			name: "field name with brackets",
+			filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "tags[0]", Operator: filter.CompareOperatorIsEqualTo, Value: "a"},
+			}},
+		},
+		{
+			name: "field name with a closing bracket",
+			filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+				{Name: "a]b", Operator: filter.CompareOperatorIsEqualTo, Value: "a"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawQuery, err := ResultSelector{Filter: tt.filter}.QueryString()
+
+			var response *errs.Response
+			if !errors.As(err, &response) || response.ErrorCode != errs.InvalidRequestError {
+				t.Errorf("QueryString() = %q, %v, want an InvalidRequestError", rawQuery, err)
+			}
+		})
+	}
+}
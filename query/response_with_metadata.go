@@ -25,16 +25,19 @@ type ResponseWithMetadata[T any] struct {
 
 // Metadata represents the metadata used in a query.
 type Metadata struct {
-	Filter  *filter.Request  `json:"filter,omitempty"`
-	Paging  *paging.Response `json:"paging,omitempty"`
-	Sorting *sorting.Request `json:"sorting,omitempty"`
+	Filter      *filter.Request     `json:"filter,omitempty"`
+	Paging      *paging.Response    `json:"paging,omitempty"`
+	Sorting     *sorting.Request    `json:"sorting,omitempty"`
+	Aggregation *filter.Aggregation `json:"aggregation,omitempty"`
 }
 
 // NewMetadata creates a new Metadata object based on the provided ResultSelector and totalResults.
-func NewMetadata(resultSelector ResultSelector, totalResults uint64) Metadata {
+// The sorting is echoed with its full ordering, in both the single column and the multi-column shape.
+// The displayable results are capped at the MaxDisplayableResults of the limits of options, if given, or of paging.DefaultLimits.
+func NewMetadata(resultSelector ResultSelector, totalResults uint64, options ...Options) Metadata {
 	return Metadata{
 		Filter:  resultSelector.Filter,
-		Paging:  paging.NewResponse(resultSelector.Paging, totalResults),
-		Sorting: resultSelector.Sorting,
+		Paging:  paging.NewCappedResponse(resultSelector.Paging, totalResults, FirstOptions(options).PagingLimits().MaxDisplayableResults),
+		Sorting: resultSelector.Sorting.Normalize(),
 	}
 }
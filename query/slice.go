@@ -0,0 +1,138 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/leetatech/leeta_golang_libraries/errs"
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+// FilterSlice returns the records matching filterRequest, in their original order.
+// Fields are resolved with filter.Resolve; see filter.Request.Match for the semantics of the operators,
+// and for the rating scales, which may be given for the fields not rated on filter.DefaultRatingScale.
+func FilterSlice[T any](records []T, filterRequest *filter.Request, scales ...filter.RatingScales) ([]T, error) {
+	matching := make([]T, 0, len(records))
+	for _, record := range records {
+		ok, err := filterRequest.Match(record, scales...)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matching = append(matching, record)
+		}
+	}
+	return matching, nil
+}
+
+// SortSlice sorts records in place by the keys of sortingRequest, resolving the columns with filter.Resolve.
+// The sort is stable, so records with equal keys keep their order, and records missing a column sort before the others
+// in ascending order, as they do in MongoDB.
+// An errs.InvalidRequestError is returned for unsupported directions and for sorting by sorting.TextScoreColumn,
+// which requires a database text index.
+func SortSlice[T any](records []T, sortingRequest *sorting.Request) error {
+	keys := sortingRequest.SortKeys()
+	descending := make([]bool, len(keys))
+	for i, key := range keys {
+		if key.Column == "" || key.Column == sorting.TextScoreColumn {
+			return errs.Body(errs.InvalidRequestError, fmt.Errorf("sort column %q is not allowed", key.Column))
+		}
+
+		switch sorting.SortDirectionFromString(string(key.Direction)) {
+		case sorting.DirectionDescending:
+			descending[i] = true
+		case sorting.NoDirection:
+			if key.Direction != sorting.NoDirection {
+				return errs.Body(errs.InvalidRequestError, fmt.Errorf("sort direction %q is not supported", key.Direction))
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	slices.SortStableFunc(records, func(a, b T) int {
+		for i, key := range keys {
+			c := compareColumn(a, b, key.Column)
+			if descending[i] {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// compareColumn orders two records by the value of a column. Missing values come first,
+// values that cannot be ordered against each other are considered equal.
+func compareColumn(a, b any, column string) int {
+	va, foundA := filter.Resolve(a, column)
+	vb, foundB := filter.Resolve(b, column)
+	foundA = foundA && va != nil
+	foundB = foundB && vb != nil
+
+	switch {
+	case !foundA && !foundB:
+		return 0
+	case !foundA:
+		return -1
+	case !foundB:
+		return 1
+	}
+
+	c, _ := filter.Compare(va, vb)
+	return c
+}
+
+// PageSlice returns the page of records selected by pagingRequest, sharing the underlying array of records.
+// All records are returned when pagingRequest is nil, and an empty slice when the page lies beyond the records.
+func PageSlice[T any](records []T, pagingRequest *paging.Request) []T {
+	if pagingRequest == nil {
+		return records
+	}
+
+	offset := min(pagingRequest.Offset(), len(records))
+	end := min(offset+pagingRequest.Limit(), len(records))
+	return records[offset:end]
+}
+
+// SelectSlice applies resultSelector to records held in memory: it filters, sorts and pages a copy of records and
+// returns the page together with the same metadata a database query would produce.
+// The paging request is validated against the limits of options, if given, or paging.DefaultLimits,
+// and the filter is matched with the rating scales of options.
+// Cursor paging is not supported and results in an errs.InvalidPageRequestError.
+func SelectSlice[T any](records []T, resultSelector ResultSelector, options ...Options) (ResponseListWithMetadata[T], error) {
+	var response ResponseListWithMetadata[T]
+	opts := FirstOptions(options)
+
+	if err := resultSelector.Paging.Validate(opts.PagingLimits()); err != nil {
+		return response, err
+	}
+	if resultSelector.Paging != nil && resultSelector.Paging.Cursor != "" {
+		return response, errs.Body(errs.InvalidPageRequestError, errors.New("cursor paging is not supported for in-memory records"))
+	}
+
+	matching, err := FilterSlice(records, resultSelector.Filter, opts.RatingScales)
+	if err != nil {
+		return response, err
+	}
+
+	if err := SortSlice(matching, resultSelector.Sorting); err != nil {
+		return response, err
+	}
+
+	response.Metadata = NewMetadata(resultSelector, uint64(len(matching)), opts)
+	if response.Metadata.Paging != nil {
+		matching = matching[:response.Metadata.Paging.TotalDisplayableResults]
+	}
+	response.Data = slices.Clip(PageSlice(matching, resultSelector.Paging))
+
+	return response, nil
+}
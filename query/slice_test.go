@@ -0,0 +1,62 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leetatech/leeta_golang_libraries/query/filter"
+	"github.com/leetatech/leeta_golang_libraries/query/paging"
+	"github.com/leetatech/leeta_golang_libraries/query/sorting"
+)
+
+type sliceTestRecord struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+func TestSelectSlice(t *testing.T) {
+	records := []sliceTestRecord{
+		{Name: "a", Price: 30},
+		{Name: "b", Price: 10},
+		{Name: "c", Price: 20},
+		{Name: "d", Price: 40},
+	}
+	selector := ResultSelector{
+		Filter: &filter.Request{Operator: filter.LogicOperatorAnd, Fields: []filter.RequestField{
+			{Name: "price", Operator: filter.CompareOperatorIsLessThan, Value: 40},
+		}},
+		Sorting: &sorting.Request{Keys: []sorting.Key{{Column: "price", Direction: sorting.DirectionAscending}}},
+		Paging:  &paging.Request{PageIndex: 0, PageSize: 2},
+	}
+
+	response, err := SelectSlice(records, selector)
+	if err != nil {
+		t.Fatalf("SelectSlice() error = %v", err)
+	}
+	if want := []sliceTestRecord{{Name: "b", Price: 10}, {Name: "c", Price: 20}}; !reflect.DeepEqual(response.Data, want) {
+		t.Errorf("SelectSlice() data = %v, want %v", response.Data, want)
+	}
+	if paging := response.Metadata.Paging; paging.TotalDisplayableResults != 3 || paging.TotalPages != 2 || !paging.HasNext {
+		t.Errorf("SelectSlice() paging = %+v, want 3 results over 2 pages", paging)
+	}
+}
+
+func TestSelectSliceLimits(t *testing.T) {
+	records := make([]sliceTestRecord, 10)
+	limits := &paging.Limits{MaxPageSize: 2, MaxDisplayableResults: 5}
+
+	if _, err := SelectSlice(records, ResultSelector{Paging: &paging.Request{PageSize: 3}}, Options{Limits: limits}); err == nil {
+		t.Error("SelectSlice() error = nil, want the page size to exceed the limits")
+	}
+	if _, err := SelectSlice(records, ResultSelector{Paging: &paging.Request{PageIndex: 3, PageSize: 2}}, Options{Limits: limits}); err == nil {
+		t.Error("SelectSlice() error = nil, want the page to be beyond the displayable results")
+	}
+
+	response, err := SelectSlice(records, ResultSelector{Paging: &paging.Request{PageIndex: 2, PageSize: 2}}, Options{Limits: limits})
+	if err != nil {
+		t.Fatalf("SelectSlice() error = %v", err)
+	}
+	if len(response.Data) != 1 || response.Metadata.Paging.TotalDisplayableResults != 5 || response.Metadata.Paging.TotalResults != 10 {
+		t.Errorf("SelectSlice() = %d records, paging %+v, want the last record of the 5 displayable ones", len(response.Data), response.Metadata.Paging)
+	}
+}
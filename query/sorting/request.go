@@ -1,11 +1,57 @@
 package sorting
 
-// Request represents a sorting request with a specified sort column and sort direction.
+// TextScoreColumn is the sort column ordering records by their relevance to the textContains filter of the request,
+// most relevant first. Its direction is ignored.
+const TextScoreColumn = "textScore"
+
+// Request represents a sorting request with an ordered list of sort keys.
 //
 // Fields:
-// - SortColumn: the column to sort on
-// - SortDirection: the direction of sorting (asc or desc)
+// - SortColumn: the column to sort on, kept for clients sending a single sort column
+// - SortDirection: the direction of sorting (asc or desc) of SortColumn
+// - Keys: the columns to sort on, in order of precedence; takes priority over SortColumn when set
 type Request struct {
-	SortColumn    string        `json:"column"`
-	SortDirection SortDirection `json:"direction"`
+	SortColumn    string        `json:"column,omitempty"`
+	SortDirection SortDirection `json:"direction,omitempty"`
+	Keys          []Key         `json:"keys,omitempty"`
+}
+
+// Key represents a single column of a sorting request.
+//
+// Fields:
+// - Column: the column to sort on
+// - Direction: the direction of sorting (asc or desc)
+type Key struct {
+	Column    string        `json:"column"`
+	Direction SortDirection `json:"direction"`
+}
+
+// SortKeys returns the ordered sort keys of the request, falling back to the single SortColumn when Keys is empty.
+func (r *Request) SortKeys() []Key {
+	if r == nil {
+		return nil
+	}
+	if len(r.Keys) > 0 {
+		return r.Keys
+	}
+	if r.SortColumn == "" {
+		return nil
+	}
+	return []Key{{Column: r.SortColumn, Direction: r.SortDirection}}
+}
+
+// Normalize returns a copy of the request with Keys holding the full ordering and
+// SortColumn/SortDirection holding its first key, so that both request shapes can be read from it.
+func (r *Request) Normalize() *Request {
+	if r == nil {
+		return nil
+	}
+
+	keys := r.SortKeys()
+	normalized := &Request{Keys: append([]Key(nil), keys...)}
+	if len(keys) > 0 {
+		normalized.SortColumn = keys[0].Column
+		normalized.SortDirection = keys[0].Direction
+	}
+	return normalized
 }
@@ -0,0 +1,92 @@
+package sorting
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRequestSortKeysFromJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Key
+	}{
+		{name: "empty", body: `{}`, want: nil},
+		{name: "legacy single column", body: `{"column":"price","direction":"desc"}`, want: []Key{{Column: "price", Direction: DirectionDescending}}},
+		{name: "legacy column without direction", body: `{"column":"price"}`, want: []Key{{Column: "price"}}},
+		{name: "legacy direction without column", body: `{"direction":"desc"}`, want: nil},
+		{
+			name: "keys",
+			body: `{"keys":[{"column":"price","direction":"desc"},{"column":"name","direction":"asc"}]}`,
+			want: []Key{{Column: "price", Direction: DirectionDescending}, {Column: "name", Direction: DirectionAscending}},
+		},
+		{
+			name: "keys take priority over the legacy column",
+			body: `{"column":"createdAt","direction":"asc","keys":[{"column":"price","direction":"desc"}]}`,
+			want: []Key{{Column: "price", Direction: DirectionDescending}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request Request
+			if err := json.Unmarshal([]byte(tt.body), &request); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", tt.body, err)
+			}
+			if got := request.SortKeys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortKeys() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestNormalizeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *Request
+		want    string
+	}{
+		{
+			name:    "legacy single column",
+			request: &Request{SortColumn: "price", SortDirection: DirectionDescending},
+			want:    `{"column":"price","direction":"desc","keys":[{"column":"price","direction":"desc"}]}`,
+		},
+		{
+			name:    "keys",
+			request: &Request{Keys: []Key{{Column: "price", Direction: DirectionDescending}, {Column: "name", Direction: DirectionAscending}}},
+			want:    `{"column":"price","direction":"desc","keys":[{"column":"price","direction":"desc"},{"column":"name","direction":"asc"}]}`,
+		},
+		{
+			name:    "empty",
+			request: &Request{},
+			want:    `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.request.Normalize())
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("json.Marshal(Normalize()) = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestNormalizeKeepsRequest(t *testing.T) {
+	request := &Request{Keys: []Key{{Column: "price", Direction: DirectionDescending}}}
+
+	normalized := request.Normalize()
+	normalized.Keys[0].Column = "name"
+
+	if request.Keys[0].Column != "price" {
+		t.Errorf("changing the normalized keys changed the request keys to %+v", request.Keys)
+	}
+	if (*Request)(nil).Normalize() != nil {
+		t.Errorf("Normalize() of a nil request is not nil")
+	}
+}
@@ -1,9 +1,25 @@
 package sorting
 
 // Response represents the response structure for sorting column and direction.
-// SortingColumn stores the name of the column which was used for sorting.
-// SortingDirection stores the direction which was applied by the sorting.
+// SortingColumn stores the name of the column which was used for sorting first.
+// SortingDirection stores the direction which was applied by the sorting on SortingColumn.
+// Keys stores every column, in order of precedence, which was used for sorting.
 type Response struct {
 	SortingColumn    string        `json:"column"`
 	SortingDirection SortDirection `json:"direction"`
+	Keys             []Key         `json:"keys,omitempty"`
+}
+
+// NewResponse creates the sorting response echoing the ordering applied for request.
+func NewResponse(request *Request) *Response {
+	normalized := request.Normalize()
+	if normalized == nil {
+		return nil
+	}
+
+	return &Response{
+		SortingColumn:    normalized.SortColumn,
+		SortingDirection: normalized.SortDirection,
+		Keys:             normalized.Keys,
+	}
 }
@@ -0,0 +1,164 @@
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+// MultipartForm is a multipart/form-data request body made of form fields and files, as expected by upload endpoints.
+// The form is encoded in memory before being sent, so that it can be sent again on retries.
+//
+// Fields:
+// - Fields: the form fields, sent before the files
+// - Files: the files, sent in order
+type MultipartForm struct {
+	Fields url.Values
+	Files  []MultipartFile
+}
+
+// MultipartFile is a file of a MultipartForm.
+//
+// Fields:
+// - FieldName: the name of the form field holding the file
+// - FileName: the name of the file
+// - ContentType: the content type of the file; application/octet-stream when empty
+// - Content: the content of the file, read once when the form is encoded
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Content     io.Reader
+}
+
+// requestBody is an encoded request body.
+// Bodies held in memory are replayable; streamed bodies can only be sent again if their reader can seek back to its start.
+type requestBody struct {
+	contentType string
+	content     []byte
+	stream      io.Reader
+	start       int64
+}
+
+// encodeBody encodes data as a request body according to its type:
+//   - nil: no body, and no Content-Type header
+//   - io.Reader: streamed as it is, as application/octet-stream
+//   - url.Values: encoded as application/x-www-form-urlencoded
+//   - MultipartForm or *MultipartForm: encoded as multipart/form-data
+//   - anything else: marshalled as application/json
+//
+// The Content-Type header can be overridden with WithRequestHeader, e.g. to stream an image with its own content type.
+func encodeBody(data any) (*requestBody, error) {
+	switch v := data.(type) {
+	case nil:
+		return &requestBody{}, nil
+
+	case io.Reader:
+		body := &requestBody{contentType: "application/octet-stream", stream: v, start: -1}
+		if seeker, ok := v.(io.Seeker); ok {
+			start, err := seeker.Seek(0, io.SeekCurrent)
+			if err == nil {
+				body.start = start
+			}
+		}
+		return body, nil
+
+	case url.Values:
+		return &requestBody{contentType: "application/x-www-form-urlencoded", content: []byte(v.Encode())}, nil
+
+	case MultipartForm:
+		return encodeMultipart(&v)
+	case *MultipartForm:
+		return encodeMultipart(v)
+
+	default:
+		content, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		return &requestBody{contentType: "application/json", content: content}, nil
+	}
+}
+
+// encodeMultipart encodes a multipart form in memory.
+func encodeMultipart(form *MultipartForm) (*requestBody, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for name, values := range form.Fields {
+		for _, value := range values {
+			if err := writer.WriteField(name, value); err != nil {
+				return nil, fmt.Errorf("failed to write multipart field %q: %w", name, err)
+			}
+		}
+	}
+
+	for _, file := range form.Files {
+		if file.Content == nil {
+			return nil, fmt.Errorf("multipart file %q has no content", file.FileName)
+		}
+
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(file.FieldName), escapeQuotes(file.FileName)))
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create multipart file %q: %w", file.FileName, err)
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return nil, fmt.Errorf("failed to write multipart file %q: %w", file.FileName, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart form: %w", err)
+	}
+
+	return &requestBody{contentType: writer.FormDataContentType(), content: buf.Bytes()}, nil
+}
+
+// quoteEscaper escapes the characters that cannot appear in a quoted Content-Disposition parameter,
+// as mime/multipart does for the headers it builds itself.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes s to be used as a quoted Content-Disposition parameter.
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// replayable reports whether the body can be sent more than once.
+func (b *requestBody) replayable() bool {
+	return b.stream == nil || b.start >= 0
+}
+
+// reader returns a reader of the body for a new attempt, or nil if there is no body.
+// Streams are left open for the caller to close, even once sent.
+func (b *requestBody) reader() (io.Reader, error) {
+	if b.stream == nil {
+		if b.content == nil {
+			return nil, nil
+		}
+		return bytes.NewReader(b.content), nil
+	}
+
+	if b.start >= 0 {
+		if _, err := b.stream.(io.Seeker).Seek(b.start, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+	}
+	if _, ok := b.stream.(io.Closer); ok {
+		return io.NopCloser(b.stream), nil
+	}
+	return b.stream, nil
+}
@@ -0,0 +1,207 @@
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// receivedRequest is a request as received by a test server.
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// testBodyServer responds with the given statuses in turn, then with 200 OK, recording the requests it receives.
+func testBodyServer(t *testing.T, statuses ...int) (*httptest.Server, *[]receivedRequest) {
+	t.Helper()
+
+	var received []receivedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request body: %v", err)
+		}
+		received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+		if n := len(received); n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+func TestDoEncodesBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            any
+		options         []RequestOption
+		wantContentType string
+		wantBody        string
+	}{
+		{name: "nil", data: nil, wantContentType: "", wantBody: ""},
+		{name: "json", data: map[string]any{"id": 1}, wantContentType: "application/json", wantBody: `{"id":1}`},
+		{name: "form", data: url.Values{"name": {"a&b"}, "tag": {"x", "y"}}, wantContentType: "application/x-www-form-urlencoded", wantBody: "name=a%26b&tag=x&tag=y"},
+		{name: "stream", data: strings.NewReader("raw bytes"), wantContentType: "application/octet-stream", wantBody: "raw bytes"},
+		{
+			name:            "stream with its own content type",
+			data:            strings.NewReader("<svg/>"),
+			options:         []RequestOption{WithRequestHeader("Content-Type", "image/svg+xml")},
+			wantContentType: "image/svg+xml",
+			wantBody:        "<svg/>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := testBodyServer(t)
+
+			resp, err := New().Do(context.Background(), http.MethodPost, tt.data, server.URL, tt.options...)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if len(*received) != 1 {
+				t.Fatalf("requests = %d, want 1", len(*received))
+			}
+			got := (*received)[0]
+			if contentType := got.header.Get("Content-Type"); contentType != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", contentType, tt.wantContentType)
+			}
+			if tt.wantContentType == "" {
+				if _, ok := got.header["Content-Type"]; ok {
+					t.Errorf("Content-Type header sent for a request without a body")
+				}
+			}
+			if string(got.body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", got.body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestDoEncodesMultipartForm(t *testing.T) {
+	server, received := testBodyServer(t)
+
+	form := &MultipartForm{
+		Fields: url.Values{"title": {"Receipt"}},
+		Files: []MultipartFile{
+			{FieldName: "file", FileName: `receipt "march".pdf`, ContentType: "application/pdf", Content: strings.NewReader("%PDF-1.7")},
+			{FieldName: "attachment", FileName: "notes.txt", Content: strings.NewReader("notes")},
+		},
+	}
+	resp, err := New().Do(context.Background(), http.MethodPost, form, server.URL)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	got := (*received)[0]
+	mediaType, params, err := mime.ParseMediaType(got.header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Content-Type = %q, want multipart/form-data", got.header.Get("Content-Type"))
+	}
+
+	parsed, err := multipart.NewReader(bytes.NewReader(got.body), params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("failed to read the multipart form: %v", err)
+	}
+	if want := map[string][]string{"title": {"Receipt"}}; !reflect.DeepEqual(parsed.Value, want) {
+		t.Errorf("form fields = %v, want %v", parsed.Value, want)
+	}
+
+	wantFiles := []struct {
+		fieldName, fileName, contentType, content string
+	}{
+		{fieldName: "file", fileName: `receipt "march".pdf`, contentType: "application/pdf", content: "%PDF-1.7"},
+		{fieldName: "attachment", fileName: "notes.txt", contentType: "application/octet-stream", content: "notes"},
+	}
+	for _, want := range wantFiles {
+		files := parsed.File[want.fieldName]
+		if len(files) != 1 {
+			t.Fatalf("files in %q = %d, want 1", want.fieldName, len(files))
+		}
+		file := files[0]
+		if file.Filename != want.fileName || file.Header.Get("Content-Type") != want.contentType {
+			t.Errorf("file in %q = %q (%s), want %q (%s)", want.fieldName, file.Filename, file.Header.Get("Content-Type"), want.fileName, want.contentType)
+		}
+		f, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open file in %q: %v", want.fieldName, err)
+		}
+		content, _ := io.ReadAll(f)
+		f.Close()
+		if string(content) != want.content {
+			t.Errorf("file in %q = %q, want %q", want.fieldName, content, want.content)
+		}
+	}
+}
+
+func TestDoRetryableResendsBody(t *testing.T) {
+	noWait := RetryPolicy{MaxAttempts: 3, Sleep: func(ctx context.Context, d time.Duration) error { return nil }}
+
+	t.Run("form", func(t *testing.T) {
+		server, received := testBodyServer(t, http.StatusServiceUnavailable)
+
+		resp, err := New(WithRetryPolicy(noWait)).DoRetryable(context.Background(), http.MethodPut, url.Values{"a": {"1"}}, server.URL)
+		if err != nil {
+			t.Fatalf("DoRetryable() error = %v", err)
+		}
+		resp.Body.Close()
+
+		if len(*received) != 2 {
+			t.Fatalf("requests = %d, want 2", len(*received))
+		}
+		for i, got := range *received {
+			if string(got.body) != "a=1" || got.header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+				t.Errorf("attempt %d body = %q (%s), want the form", i+1, got.body, got.header.Get("Content-Type"))
+			}
+		}
+	})
+
+	t.Run("seekable stream rewound to where it started", func(t *testing.T) {
+		server, received := testBodyServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+
+		stream := strings.NewReader("skipped:payload")
+		stream.Seek(int64(len("skipped:")), io.SeekStart)
+		resp, err := New(WithRetryPolicy(noWait)).DoRetryable(context.Background(), http.MethodPut, stream, server.URL)
+		if err != nil {
+			t.Fatalf("DoRetryable() error = %v", err)
+		}
+		resp.Body.Close()
+
+		if len(*received) != 3 {
+			t.Fatalf("requests = %d, want 3", len(*received))
+		}
+		for i, got := range *received {
+			if string(got.body) != "payload" {
+				t.Errorf("attempt %d body = %q, want %q", i+1, got.body, "payload")
+			}
+		}
+	})
+
+	t.Run("stream that cannot seek", func(t *testing.T) {
+		server, received := testBodyServer(t, http.StatusServiceUnavailable)
+
+		stream := io.MultiReader(strings.NewReader("payload"))
+		_, err := New(WithRetryPolicy(noWait)).DoRetryable(context.Background(), http.MethodPut, stream, server.URL)
+		if err == nil {
+			t.Fatalf("DoRetryable() error = nil, want the 503 response")
+		}
+
+		if len(*received) != 1 || string((*received)[0].body) != "payload" {
+			t.Errorf("requests = %d, want a single request sending the stream", len(*received))
+		}
+	})
+}
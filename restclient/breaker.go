@@ -0,0 +1,272 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker of a host.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through while counting their failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests immediately until the cool-down period has elapsed.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through to probe whether the host recovered.
+	CircuitHalfOpen
+)
+
+// String implements the fmt.Stringer interface.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// ErrCircuitOpen matches every *CircuitOpenError with errors.Is.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned without sending the request when the circuit breaker of its host is open,
+// or half-open with all of its trial requests in flight.
+//
+// Fields:
+// - Host: the host the request was meant for
+// - State: the state of the circuit breaker of the host
+// - Until: when the circuit breaker lets trial requests through again; zero when half-open
+type CircuitOpenError struct {
+	Host  string
+	State CircuitState
+	Until time.Time
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is %s", e.Host, e.State)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitBreakerSettings configures the circuit breakers of a client, one per host.
+//
+// A closed circuit opens when, within Window, at least MinRequests requests were sent and the share of them that failed
+// reached FailureRateThreshold. An open circuit fails every request with a *CircuitOpenError for CoolDown,
+// then turns half-open and lets HalfOpenRequests trial requests through: it closes once they all succeeded
+// and opens again as soon as one fails. Requests canceled by their caller are not counted, as they tell nothing
+// about the health of the host: a canceled trial request only frees its place for another one.
+//
+// Fields:
+//   - FailureRateThreshold: the share of failed requests, between 0 and 1, opening the circuit
+//   - MinRequests: the number of requests within a window below which the circuit does not open
+//   - Window: the period over which requests are counted, counts starting over at the end of every window
+//   - CoolDown: how long the circuit stays open
+//   - HalfOpenRequests: the number of trial requests of a half-open circuit
+//   - IsFailure: reports whether a request that was not canceled failed; by default transport errors and 5xx responses are failures
+//   - OnStateChange: called, outside of any lock, whenever the circuit of a host changes state
+//   - Now: returns the current time; time.Now when nil
+//
+// Zero fields take their value from DefaultCircuitBreakerSettings.
+type CircuitBreakerSettings struct {
+	FailureRateThreshold float64
+	MinRequests          int
+	Window               time.Duration
+	CoolDown             time.Duration
+	HalfOpenRequests     int
+	IsFailure            func(resp *http.Response, err error) bool
+	OnStateChange        func(host string, from, to CircuitState)
+	Now                  func() time.Time
+}
+
+// DefaultCircuitBreakerSettings opens the circuit of a host when half of at least 10 requests within 30s failed,
+// and probes it again with a single request after 15s.
+var DefaultCircuitBreakerSettings = CircuitBreakerSettings{
+	FailureRateThreshold: 0.5,
+	MinRequests:          10,
+	Window:               30 * time.Second,
+	CoolDown:             15 * time.Second,
+	HalfOpenRequests:     1,
+}
+
+// WithCircuitBreaker guards every host the client sends requests to with a circuit breaker.
+// Requests refused by an open circuit are not retried.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *Client) {
+		c.breakers = newCircuitBreakers(settings)
+	}
+}
+
+// circuitBreakers holds the circuit breaker of every host.
+type circuitBreakers struct {
+	settings CircuitBreakerSettings
+
+	mu    sync.Mutex
+	hosts map[string]*circuitBreaker
+}
+
+// circuitBreaker is the circuit breaker of a single host.
+// generation changes with every state change, so that the outcome of a request is only counted in the state it was allowed in.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	state            CircuitState
+	generation       uint64
+	windowStart      time.Time
+	requests         int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenPassed   int
+}
+
+// newCircuitBreakers creates the circuit breakers of a client, filling the zero settings with their default.
+func newCircuitBreakers(settings CircuitBreakerSettings) *circuitBreakers {
+	defaults := DefaultCircuitBreakerSettings
+	if settings.FailureRateThreshold <= 0 {
+		settings.FailureRateThreshold = defaults.FailureRateThreshold
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = defaults.MinRequests
+	}
+	if settings.Window <= 0 {
+		settings.Window = defaults.Window
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = defaults.CoolDown
+	}
+	if settings.HalfOpenRequests <= 0 {
+		settings.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = isFailure
+	}
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+
+	return &circuitBreakers{settings: settings, hosts: make(map[string]*circuitBreaker)}
+}
+
+// isFailure reports whether a request failed: it ended with a transport error or a 5xx status.
+func isFailure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= 500
+}
+
+// host returns the circuit breaker of host, creating it on first use.
+func (g *circuitBreakers) host(host string) *circuitBreaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.hosts[host]
+	if !ok {
+		b = &circuitBreaker{windowStart: g.settings.Now()}
+		g.hosts[host] = b
+	}
+	return b
+}
+
+// allow reports whether a request may be sent to host, returning a *CircuitOpenError if not.
+// The returned generation must be passed to record with the outcome of the request.
+func (g *circuitBreakers) allow(host string) (uint64, error) {
+	b := g.host(host)
+	now := g.settings.Now()
+
+	b.mu.Lock()
+	from := b.state
+	if b.state == CircuitOpen && !now.Before(b.openedAt.Add(g.settings.CoolDown)) {
+		b.transition(CircuitHalfOpen, now)
+	}
+	to := b.state
+
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = &CircuitOpenError{Host: host, State: CircuitOpen, Until: b.openedAt.Add(g.settings.CoolDown)}
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight+b.halfOpenPassed >= g.settings.HalfOpenRequests {
+			err = &CircuitOpenError{Host: host, State: CircuitHalfOpen}
+		} else {
+			b.halfOpenInFlight++
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+
+	g.notify(host, from, to)
+	return generation, err
+}
+
+// record counts the outcome of a request to host allowed in the given generation.
+// Canceled requests are not counted; a canceled trial request of a half-open circuit only releases its place.
+func (g *circuitBreakers) record(host string, generation uint64, resp *http.Response, err error) {
+	canceled := errors.Is(err, context.Canceled)
+	failed := !canceled && g.settings.IsFailure(resp, err)
+	b := g.host(host)
+	now := g.settings.Now()
+
+	b.mu.Lock()
+	from := b.state
+	if generation == b.generation {
+		switch b.state {
+		case CircuitClosed:
+			if canceled {
+				break
+			}
+			if now.Sub(b.windowStart) >= g.settings.Window {
+				b.windowStart, b.requests, b.failures = now, 0, 0
+			}
+			b.requests++
+			if failed {
+				b.failures++
+			}
+			if b.requests >= g.settings.MinRequests && float64(b.failures)/float64(b.requests) >= g.settings.FailureRateThreshold {
+				b.transition(CircuitOpen, now)
+			}
+
+		case CircuitHalfOpen:
+			b.halfOpenInFlight--
+			if canceled {
+				break
+			}
+			if failed {
+				b.transition(CircuitOpen, now)
+			} else if b.halfOpenPassed++; b.halfOpenPassed >= g.settings.HalfOpenRequests {
+				b.transition(CircuitClosed, now)
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	g.notify(host, from, to)
+}
+
+// notify calls the state change hook if the state changed.
+func (g *circuitBreakers) notify(host string, from, to CircuitState) {
+	if from != to && g.settings.OnStateChange != nil {
+		g.settings.OnStateChange(host, from, to)
+	}
+}
+
+// transition moves the circuit breaker to state, resetting its counts. The caller must hold its lock.
+func (b *circuitBreaker) transition(state CircuitState, now time.Time) {
+	b.state = state
+	b.generation++
+	b.windowStart, b.requests, b.failures = now, 0, 0
+	b.halfOpenInFlight, b.halfOpenPassed = 0, 0
+	if state == CircuitOpen {
+		b.openedAt = now
+	}
+}
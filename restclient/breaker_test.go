@@ -0,0 +1,239 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testHost = "api.example.com"
+
+// testBreakers holds circuit breakers running on a fake clock and recording their state changes.
+type testBreakers struct {
+	*circuitBreakers
+	now     time.Time
+	changes []string
+}
+
+func newTestBreakers(settings CircuitBreakerSettings) *testBreakers {
+	tb := &testBreakers{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	settings.Now = func() time.Time { return tb.now }
+	settings.OnStateChange = func(host string, from, to CircuitState) {
+		tb.changes = append(tb.changes, fmt.Sprintf("%s->%s", from, to))
+	}
+	tb.circuitBreakers = newCircuitBreakers(settings)
+	return tb
+}
+
+// send records a request allowed by the breakers with the given outcome.
+func (tb *testBreakers) send(t *testing.T, status int, err error) {
+	t.Helper()
+
+	generation, allowErr := tb.allow(testHost)
+	if allowErr != nil {
+		t.Fatalf("allow() error = %v", allowErr)
+	}
+	tb.record(testHost, generation, responseWithStatus(status, err), err)
+}
+
+func (tb *testBreakers) state() CircuitState {
+	b := tb.host(testHost)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// responseWithStatus returns a response with status, or nil for requests failing with err.
+func responseWithStatus(status int, err error) *http.Response {
+	if err != nil {
+		return nil
+	}
+	return &http.Response{StatusCode: status}
+}
+
+var errTransport = errors.New("connection reset")
+
+var testBreakerSettings = CircuitBreakerSettings{
+	FailureRateThreshold: 0.5,
+	MinRequests:          4,
+	Window:               time.Minute,
+	CoolDown:             10 * time.Second,
+	HalfOpenRequests:     2,
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	tb := newTestBreakers(testBreakerSettings)
+
+	tb.send(t, http.StatusOK, nil)
+	tb.send(t, http.StatusOK, nil)
+	tb.send(t, http.StatusServiceUnavailable, nil)
+	if got := tb.state(); got != CircuitClosed {
+		t.Fatalf("state after 3 requests = %s, want closed", got)
+	}
+	tb.send(t, 0, errTransport)
+	if got := tb.state(); got != CircuitOpen {
+		t.Fatalf("state after 2 failures out of 4 = %s, want open", got)
+	}
+
+	_, err := tb.allow(testHost)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v, want a CircuitOpenError", err)
+	}
+	if want := tb.now.Add(testBreakerSettings.CoolDown); !openErr.Until.Equal(want) {
+		t.Errorf("CircuitOpenError.Until = %v, want %v", openErr.Until, want)
+	}
+
+	tb.now = tb.now.Add(testBreakerSettings.CoolDown)
+	first, err := tb.allow(testHost)
+	if err != nil {
+		t.Fatalf("allow() after the cool-down error = %v", err)
+	}
+	second, err := tb.allow(testHost)
+	if err != nil {
+		t.Fatalf("allow() of the second trial request error = %v", err)
+	}
+	if _, err := tb.allow(testHost); !errors.As(err, &openErr) || openErr.State != CircuitHalfOpen {
+		t.Fatalf("allow() beyond the trial requests error = %v, want a half-open CircuitOpenError", err)
+	}
+
+	tb.record(testHost, first, &http.Response{StatusCode: http.StatusOK}, nil)
+	if got := tb.state(); got != CircuitHalfOpen {
+		t.Fatalf("state after one passed trial request = %s, want half-open", got)
+	}
+	tb.record(testHost, second, &http.Response{StatusCode: http.StatusOK}, nil)
+	if got := tb.state(); got != CircuitClosed {
+		t.Fatalf("state after all trial requests passed = %s, want closed", got)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if !reflect.DeepEqual(tb.changes, want) {
+		t.Errorf("state changes = %v, want %v", tb.changes, want)
+	}
+}
+
+func TestCircuitBreakerReopensOnFailedTrial(t *testing.T) {
+	tb := newTestBreakers(testBreakerSettings)
+	for range 4 {
+		tb.send(t, http.StatusInternalServerError, nil)
+	}
+
+	tb.now = tb.now.Add(testBreakerSettings.CoolDown)
+	tb.send(t, http.StatusBadGateway, nil)
+	if got := tb.state(); got != CircuitOpen {
+		t.Fatalf("state after a failed trial request = %s, want open", got)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open"}
+	if !reflect.DeepEqual(tb.changes, want) {
+		t.Errorf("state changes = %v, want %v", tb.changes, want)
+	}
+}
+
+func TestCircuitBreakerIgnoresCanceledRequests(t *testing.T) {
+	t.Run("closed", func(t *testing.T) {
+		tb := newTestBreakers(CircuitBreakerSettings{FailureRateThreshold: 0.5, MinRequests: 2, Window: time.Minute, CoolDown: time.Second})
+
+		tb.send(t, 0, errTransport)
+		tb.send(t, 0, context.Canceled)
+		tb.send(t, 0, fmt.Errorf("request: %w", context.Canceled))
+		if got := tb.state(); got != CircuitClosed {
+			t.Fatalf("state after one failure and canceled requests = %s, want closed", got)
+		}
+
+		tb.send(t, 0, errTransport)
+		if got := tb.state(); got != CircuitOpen {
+			t.Fatalf("state after two failures = %s, want open", got)
+		}
+	})
+
+	t.Run("half-open", func(t *testing.T) {
+		tb := newTestBreakers(CircuitBreakerSettings{FailureRateThreshold: 0.5, MinRequests: 1, Window: time.Minute, CoolDown: time.Second, HalfOpenRequests: 1})
+		tb.send(t, 0, errTransport)
+
+		tb.now = tb.now.Add(time.Second)
+		tb.send(t, 0, context.Canceled)
+		if got := tb.state(); got != CircuitHalfOpen {
+			t.Fatalf("state after a canceled trial request = %s, want half-open", got)
+		}
+
+		// the canceled request freed its place for another trial request
+		tb.send(t, http.StatusOK, nil)
+		if got := tb.state(); got != CircuitClosed {
+			t.Fatalf("state after a passed trial request = %s, want closed", got)
+		}
+	})
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	tb := newTestBreakers(testBreakerSettings)
+
+	tb.send(t, http.StatusInternalServerError, nil)
+	tb.send(t, http.StatusInternalServerError, nil)
+	tb.send(t, http.StatusInternalServerError, nil)
+
+	// the failures of the previous window are forgotten
+	tb.now = tb.now.Add(testBreakerSettings.Window)
+	tb.send(t, http.StatusInternalServerError, nil)
+	if got := tb.state(); got != CircuitClosed {
+		t.Fatalf("state after a new window started = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleOutcomes(t *testing.T) {
+	tb := newTestBreakers(CircuitBreakerSettings{FailureRateThreshold: 0.5, MinRequests: 1, Window: time.Minute, CoolDown: time.Second, HalfOpenRequests: 1})
+
+	stale, err := tb.allow(testHost)
+	if err != nil {
+		t.Fatalf("allow() error = %v", err)
+	}
+	tb.send(t, 0, errTransport)
+
+	tb.now = tb.now.Add(time.Second)
+	trial, err := tb.allow(testHost)
+	if err != nil {
+		t.Fatalf("allow() after the cool-down error = %v", err)
+	}
+
+	// a request allowed before the circuit opened does not count as a trial request
+	tb.record(testHost, stale, &http.Response{StatusCode: http.StatusOK}, nil)
+	if got := tb.state(); got != CircuitHalfOpen {
+		t.Fatalf("state after a stale outcome = %s, want half-open", got)
+	}
+	tb.record(testHost, trial, &http.Response{StatusCode: http.StatusOK}, nil)
+	if got := tb.state(); got != CircuitClosed {
+		t.Fatalf("state after the trial request passed = %s, want closed", got)
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithCircuitBreaker(CircuitBreakerSettings{FailureRateThreshold: 1, MinRequests: 2}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, Sleep: func(ctx context.Context, d time.Duration) error { return nil }}),
+	)
+
+	_, err := c.DoRetryable(context.Background(), http.MethodGet, nil, server.URL)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("DoRetryable() error = %v, want ErrCircuitOpen", err)
+	}
+	var openErr *CircuitOpenError
+	if serverURL, _ := url.Parse(server.URL); !errors.As(err, &openErr) || openErr.Host != serverURL.Host {
+		t.Errorf("DoRetryable() error = %v, want a CircuitOpenError for %s", err, server.URL)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2: requests refused by the open circuit are not retried", requests)
+	}
+}
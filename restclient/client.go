@@ -0,0 +1,174 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultTimeout is the time limit of a request, including reading its response body, used when no timeout is configured.
+const DefaultTimeout = 30 * time.Second
+
+// Client sends JSON requests over HTTP. It is safe for concurrent use and is meant to be created once per upstream service.
+type Client struct {
+	httpClient  *http.Client
+	baseURL     string
+	headers     http.Header
+	retryPolicy RetryPolicy
+	logger      *zerolog.Logger
+	breakers    *circuitBreakers
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout sets the time limit of every request sent by the client, including reading its response body.
+// A timeout of 0 disables the limit, leaving requests bound by their context only.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithTransport sets the transport used to send requests, such as a tuned *http.Transport.
+// http.DefaultTransport is used otherwise.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithBaseURL sets the URL that relative request URLs, such as "/states/", are appended to.
+// Absolute request URLs are sent unchanged.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHeader adds a header sent with every request, such as an API key.
+// Headers set by the client itself, such as Content-Type, take precedence.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithRetryPolicy sets the retry policy of DoRetryable. Zero delays of policy take their value from DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = newRetryPolicy(policy)
+	}
+}
+
+// WithLogger sets the logger requests and retries are logged to. The global zerolog logger is used otherwise.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(c *Client) {
+		c.logger = &logger
+	}
+}
+
+// RequestOption configures a single request.
+type RequestOption func(*http.Request)
+
+// WithRequestHeader sets a header of the request, replacing any default header of the same name.
+func WithRequestHeader(key, value string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header of the request, which lets the server recognize
+// repeated attempts and, under RetryWithIdempotencyKey, allows POST and PATCH requests to be retried.
+func WithIdempotencyKey(key string) RequestOption {
+	return WithRequestHeader(IdempotencyKeyHeader, key)
+}
+
+// New creates a Client configured with the given options, defaulting to DefaultTimeout and DefaultRetryPolicy.
+func New(options ...Option) *Client {
+	c := &Client{
+		httpClient:  &http.Client{Timeout: DefaultTimeout},
+		headers:     make(http.Header),
+		retryPolicy: DefaultRetryPolicy,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// defaultClient is the client used by the package level functions.
+var defaultClient = New()
+
+// log returns the logger of the client.
+func (c *Client) log() *zerolog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return &log.Logger
+}
+
+// resolveURL returns the URL a request to rawURL is sent to: rawURL itself if it is absolute,
+// or rawURL appended to the base URL of the client.
+func (c *Client) resolveURL(rawURL string) string {
+	if c.baseURL == "" {
+		return rawURL
+	}
+	// unparsable URLs are left for http.NewRequest to report
+	if u, err := url.Parse(rawURL); err != nil || u.IsAbs() {
+		return rawURL
+	}
+	return c.baseURL + "/" + strings.TrimPrefix(rawURL, "/")
+}
+
+// newRequest creates a request to rawURL carrying the default headers of the client and the body, if any, then applies options to it.
+func (c *Client) newRequest(ctx context.Context, method, rawURL string, body *requestBody, options ...RequestOption) (*http.Request, error) {
+	content, err := body.reader()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.resolveURL(rawURL), content)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if body.contentType != "" {
+		req.Header.Set("Content-Type", body.contentType)
+	}
+
+	for _, option := range options {
+		option(req)
+	}
+
+	return req, nil
+}
+
+// send sends a single request, through the circuit breaker of its host when the client has circuit breakers.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.breakers == nil {
+		return c.httpClient.Do(req)
+	}
+
+	generation, err := c.breakers.allow(req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	c.breakers.record(req.URL.Host, generation, resp, err)
+	return resp, err
+}
@@ -0,0 +1,54 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientResolveURL(t *testing.T) {
+	c := New(WithBaseURL("https://api.example.com/v1/"))
+
+	tests := []struct {
+		name   string
+		rawURL string
+		want   string
+	}{
+		{name: "relative path", rawURL: "users", want: "https://api.example.com/v1/users"},
+		{name: "rooted path", rawURL: "/users", want: "https://api.example.com/v1/users"},
+		{name: "URL in the query", rawURL: "/redirect?next=https://x", want: "https://api.example.com/v1/redirect?next=https://x"},
+		{name: "absolute URL", rawURL: "https://other.example.com/users", want: "https://other.example.com/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.resolveURL(tt.rawURL); got != tt.want {
+				t.Errorf("resolveURL(%q) = %q, want %q", tt.rawURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientBaseURLAndHeaders(t *testing.T) {
+	var gotURL, gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		gotHeader = r.Header.Get("X-Api-Key")
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL+"/v1"), WithHeader("X-Api-Key", "default"))
+	resp, err := c.Do(context.Background(), http.MethodGet, nil, "/redirect?next=https://x", WithRequestHeader("X-Api-Key", "override"))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if want := "/v1/redirect?next=https://x"; gotURL != want {
+		t.Errorf("request URL = %q, want %q", gotURL, want)
+	}
+	if gotHeader != "override" {
+		t.Errorf("X-Api-Key header = %q, want the request header to override the default one", gotHeader)
+	}
+}
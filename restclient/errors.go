@@ -0,0 +1,45 @@
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HTTPError is returned for responses with a non-2xx status, once all attempts of a request failed.
+// Callers can branch on the status with errors.As:
+//
+//	var httpErr *restclient.HTTPError
+//	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound { ... }
+//
+// Fields:
+// - Method, URL: the method and URL of the request
+// - StatusCode: the status code of the last response
+// - Header: the headers of the last response
+// - Body: the body of the last response, truncated to a short preview
+// - Attempts: the number of times the request was sent
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       string
+	Attempts   int
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http %d: %s", e.StatusCode, e.Body)
+}
+
+// newHTTPError creates the error returned for an unsuccessful response to req, given its body.
+func newHTTPError(req *http.Request, resp *http.Response, body []byte, attempts int) *HTTPError {
+	return &HTTPError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       truncate(strings.TrimSpace(string(body))),
+		Attempts:   attempts,
+	}
+}
@@ -3,185 +3,146 @@ package restclient
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/rs/zerolog/log"
 )
 
-var defaultHTTPClient = &http.Client{}
-
 const maxErrorBodyPreview = 256
 
-func DoHTTPRequest(ctx context.Context, method string, data any, url string) (*http.Response, error) {
-	var requestBody []byte
-	if method == http.MethodPost {
-		body, err := json.Marshal(data)
-		if err != nil {
-			return nil, err
-		}
-		requestBody = body
+// DoHTTPRequest sends a single request with the default client; see Client.Do.
+func DoHTTPRequest(ctx context.Context, method string, data any, url string, options ...RequestOption) (*http.Response, error) {
+	return defaultClient.Do(ctx, method, data, url, options...)
+}
+
+// DoRetryableHTTPRequest sends a request with the default client, retrying it on failure; see Client.DoRetryable.
+func DoRetryableHTTPRequest(ctx context.Context, method string, data any, url string, options ...RequestOption) (*http.Response, error) {
+	return defaultClient.DoRetryable(ctx, method, data, url, options...)
+}
+
+// Do sends a single request expecting a JSON response, with data as its body whatever the method.
+// data is encoded according to its type: a nil data sends no body and no Content-Type header, an io.Reader is streamed,
+// url.Values are form-urlencoded, a MultipartForm is sent as multipart/form-data and anything else is marshalled to JSON.
+// Query parameters are added with WithQuery.
+// An *HTTPError is returned for responses with a non-2xx status; otherwise the caller must close the response body.
+func (c *Client) Do(ctx context.Context, method string, data any, url string, options ...RequestOption) (*http.Response, error) {
+	body, err := encodeBody(data)
+	if err != nil {
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(requestBody))
+	req, err := c.newRequest(ctx, method, url, body, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-	log.Info().Msgf("making %s request to: %s", method, req.URL)
+	c.log().Info().Msgf("making %s request to: %s", method, req.URL)
 
-	resp, err := defaultHTTPClient.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("HTTP error: %s", resp.Status)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyPreview+1))
+		return nil, newHTTPError(req, resp, body, 1)
 	}
 
 	return resp, nil
 }
 
-const (
-	defaultMaxRetries = 5
-	initialBackoff    = 200 * time.Millisecond
-)
-
-func DoRetryableHTTPRequest(ctx context.Context, method string, data any, url string) (*http.Response, error) {
-	var bodyBytes []byte
-	var err error
-
-	if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
-		bodyBytes, err = json.Marshal(data)
-		if err != nil {
-			return nil, err
-		}
+// DoRetryable sends a request expecting a JSON response, with data encoded as its body as in Do,
+// and retries it on transport errors and retryable statuses according to the retry policy of the client.
+// Streamed bodies are only retried if their io.Reader is also an io.Seeker, which is rewound before every attempt.
+// Responses are not retried when their Retry-After header asks to wait longer than the maximum delay of the policy,
+// or when the next attempt would start after the deadline of ctx.
+// An *HTTPError is returned once all attempts failed with a retryable status or for other non-2xx responses;
+// otherwise the response body is fully read and the caller must close it.
+func (c *Client) DoRetryable(ctx context.Context, method string, data any, url string, options ...RequestOption) (*http.Response, error) {
+	body, err := encodeBody(data)
+	if err != nil {
+		return nil, err
 	}
 
-	backoff := initialBackoff
+	policy := c.retryPolicy
+	maxAttempts := max(policy.MaxAttempts, 1)
+	if !body.replayable() {
+		maxAttempts = 1
+	}
 
-	for attempt := 0; attempt <= defaultMaxRetries; attempt++ {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		reqBody := bytes.NewReader(bodyBytes)
-
-		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		req, err := c.newRequest(ctx, method, url, body, options...)
 		if err != nil {
 			return nil, err
 		}
 
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("Content-Type", "application/json")
-
-		log.Info().
-			Int("attempt", attempt+1).
+		c.log().Info().
+			Int("attempt", attempt).
 			Str("method", method).
-			Str("url", url).
+			Str("url", req.URL.String()).
 			Msg("making HTTP request")
 
-		resp, err := defaultHTTPClient.Do(req)
+		resp, err := c.send(req)
+		var respBody []byte
+		if err == nil {
+			respBody, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+
+		canRetry := attempt < maxAttempts && policy.allowsRetry(req)
+
 		if err != nil {
-			if attempt == defaultMaxRetries {
+			// errors caused by the request context are not retried, unlike timeouts of a single attempt
+			if !canRetry || ctx.Err() != nil || !policy.retryableError(err) {
 				return nil, err
 			}
 
-			log.Warn().
+			delay, _ := policy.delay(attempt, nil)
+			c.log().Warn().
 				Err(err).
-				Int("attempt", attempt+1).
+				Int("attempt", attempt).
+				Dur("retry_after", delay).
 				Msg("request failed, retrying")
 
-			if err := wait(ctx, backoff); err != nil {
+			if err := policy.sleep(ctx, delay); err != nil {
 				return nil, err
 			}
-
-			backoff *= 2
 			continue
 		}
 
-		respBody, readErr := io.ReadAll(resp.Body)
-		resp.Body.Close()
-
-		if readErr != nil {
-			if attempt == defaultMaxRetries {
-				return nil, readErr
-			}
-
-			log.Warn().
-				Err(readErr).
-				Int("attempt", attempt+1).
-				Msg("failed reading response body, retrying")
-
-			if err := wait(ctx, backoff); err != nil {
-				return nil, err
-			}
-
-			backoff *= 2
-			continue
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			// Success: restore body for caller
+			resp.Body = io.NopCloser(bytes.NewBuffer(respBody))
+			return resp, nil
 		}
 
-		bodyText := strings.TrimSpace(string(respBody))
-
-		// ---------- 429 RATE LIMIT ----------
-		if resp.StatusCode == http.StatusTooManyRequests {
-			if attempt == defaultMaxRetries {
-				return nil, fmt.Errorf("http %d: %s", resp.StatusCode, truncate(bodyText))
-			}
-
-			retryDelay := parseRetryAfter(resp.Header.Get("Retry-After"), backoff)
-
-			log.Warn().
-				Int("status", resp.StatusCode).
-				Int("attempt", attempt+1).
-				Dur("retry_after", retryDelay).
-				Msg("rate limited, retrying")
-
-			if err := wait(ctx, retryDelay); err != nil {
-				return nil, err
-			}
-
-			backoff *= 2
-			continue
+		if !canRetry || !policy.retryableStatus(resp.StatusCode) {
+			return nil, newHTTPError(req, resp, respBody, attempt)
 		}
 
-		// ---------- 5xx RETRIES ----------
-		if resp.StatusCode >= 500 {
-			if attempt == defaultMaxRetries {
-				return nil, fmt.Errorf("http %d: %s", resp.StatusCode, truncate(bodyText))
-			}
-
-			log.Warn().
-				Int("status", resp.StatusCode).
-				Int("attempt", attempt+1).
-				Msg("server error, retrying")
-
-			if err := wait(ctx, backoff); err != nil {
-				return nil, err
-			}
-
-			backoff *= 2
-			continue
+		// give up rather than retry before the server asked to, or after the deadline of the request
+		delay, ok := policy.delay(attempt, resp)
+		if deadline, hasDeadline := ctx.Deadline(); !ok || hasDeadline && policy.now().Add(delay).After(deadline) {
+			return nil, newHTTPError(req, resp, respBody, attempt)
 		}
 
-		// ---------- NON-2XX FAIL FAST ----------
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return nil, fmt.Errorf("http %d: %s", resp.StatusCode, truncate(bodyText))
-		}
+		c.log().Warn().
+			Int("status", resp.StatusCode).
+			Int("attempt", attempt).
+			Dur("retry_after", delay).
+			Msg("unsuccessful response, retrying")
 
-		// Success: restore body for caller
-		resp.Body = io.NopCloser(bytes.NewBuffer(respBody))
-		return resp, nil
+		if err := policy.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
 	}
-
-	return nil, fmt.Errorf("exceeded max retries")
 }
 
 func wait(ctx context.Context, d time.Duration) error {
@@ -198,26 +159,22 @@ func wait(ctx context.Context, d time.Duration) error {
 	}
 }
 
-func parseRetryAfter(h string, fallback time.Duration) time.Duration {
+func parseRetryAfter(h string, now time.Time) (time.Duration, bool) {
 	if h == "" {
-		return fallback
+		return 0, false
 	}
 
 	// delta-seconds
 	if seconds, err := strconv.Atoi(strings.TrimSpace(h)); err == nil {
-		return time.Duration(seconds) * time.Second
+		return max(time.Duration(seconds)*time.Second, 0), true
 	}
 
 	// HTTP-date
 	if t, err := http.ParseTime(h); err == nil {
-		d := time.Until(t)
-		if d < 0 {
-			return 0
-		}
-		return d
+		return max(t.Sub(now), 0), true
 	}
 
-	return fallback
+	return 0, false
 }
 
 func truncate(s string) string {
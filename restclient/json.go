@@ -0,0 +1,69 @@
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Get sends a GET request to url and decodes the JSON response into a T.
+// Requests are sent with DoRetryable on client, or on the default client when client is nil.
+// An *HTTPError is returned for unsuccessful responses.
+func Get[T any](ctx context.Context, client *Client, url string, options ...RequestOption) (T, error) {
+	return send[T](ctx, client, http.MethodGet, nil, url, options)
+}
+
+// Post sends body in a POST request to url, encoded as in Client.Do, and decodes the JSON response into a Resp.
+// Under the default retry policy, POST requests are only retried when they carry an idempotency key (see WithIdempotencyKey).
+// Requests are sent with DoRetryable on client, or on the default client when client is nil.
+// An *HTTPError is returned for unsuccessful responses.
+func Post[Req, Resp any](ctx context.Context, client *Client, url string, body Req, options ...RequestOption) (Resp, error) {
+	return send[Resp](ctx, client, http.MethodPost, body, url, options)
+}
+
+// Put sends body in a PUT request to url, encoded as in Client.Do, and decodes the JSON response into a Resp.
+// Requests are sent with DoRetryable on client, or on the default client when client is nil.
+// An *HTTPError is returned for unsuccessful responses.
+func Put[Req, Resp any](ctx context.Context, client *Client, url string, body Req, options ...RequestOption) (Resp, error) {
+	return send[Resp](ctx, client, http.MethodPut, body, url, options)
+}
+
+// Patch sends body in a PATCH request to url, encoded as in Client.Do, and decodes the JSON response into a Resp.
+// Under the default retry policy, PATCH requests are only retried when they carry an idempotency key (see WithIdempotencyKey).
+// Requests are sent with DoRetryable on client, or on the default client when client is nil.
+// An *HTTPError is returned for unsuccessful responses.
+func Patch[Req, Resp any](ctx context.Context, client *Client, url string, body Req, options ...RequestOption) (Resp, error) {
+	return send[Resp](ctx, client, http.MethodPatch, body, url, options)
+}
+
+// Delete sends a DELETE request to url and decodes the JSON response, if any, into a T.
+// Requests are sent with DoRetryable on client, or on the default client when client is nil.
+// An *HTTPError is returned for unsuccessful responses.
+func Delete[T any](ctx context.Context, client *Client, url string, options ...RequestOption) (T, error) {
+	return send[T](ctx, client, http.MethodDelete, nil, url, options)
+}
+
+// send sends a request with DoRetryable and decodes its JSON response into a T.
+// Empty responses, such as 204 No Content, leave the result at its zero value.
+func send[T any](ctx context.Context, client *Client, method string, data any, url string, options []RequestOption) (T, error) {
+	var result T
+
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.DoRetryable(ctx, method, data, url, options...)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return result, fmt.Errorf("failed to decode response of %s %s: %w", method, resp.Request.URL, err)
+	}
+
+	return result, nil
+}
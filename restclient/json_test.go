@@ -0,0 +1,150 @@
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    testUser
+		wantErr bool
+	}{
+		{name: "json response", status: http.StatusOK, body: `{"id":1,"name":"Ada"}`, want: testUser{ID: 1, Name: "Ada"}},
+		{name: "no content", status: http.StatusNoContent, want: testUser{}},
+		{name: "malformed response", status: http.StatusOK, body: `{"id":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("method = %s, want GET", r.Method)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := Get[testUser](context.Background(), nil, server.URL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Get() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("  user not found\n"))
+	}))
+	defer server.Close()
+
+	_, err := Get[testUser](context.Background(), New(), server.URL+"/users/1")
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Get() error = %v, want an HTTPError", err)
+	}
+	want := HTTPError{
+		Method:     http.MethodGet,
+		URL:        server.URL + "/users/1",
+		StatusCode: http.StatusNotFound,
+		Body:       "user not found",
+		Attempts:   1,
+	}
+	if httpErr.Method != want.Method || httpErr.URL != want.URL || httpErr.StatusCode != want.StatusCode || httpErr.Body != want.Body || httpErr.Attempts != want.Attempts {
+		t.Errorf("Get() error = %+v, want %+v", httpErr, want)
+	}
+	if got := httpErr.Header.Get("X-Request-Id"); got != "req-1" {
+		t.Errorf("HTTPError.Header X-Request-Id = %q, want %q", got, "req-1")
+	}
+	if got, want := httpErr.Error(), "http 404: user not found"; got != want {
+		t.Errorf("HTTPError.Error() = %q, want %q", got, want)
+	}
+}
+
+func TestGetHTTPErrorAfterRetries(t *testing.T) {
+	server, requests := testRetryServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	c := New(WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Sleep: func(ctx context.Context, d time.Duration) error { return nil }}))
+
+	_, err := Get[testUser](context.Background(), c, server.URL)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable || httpErr.Attempts != 3 {
+		t.Fatalf("Get() error = %v, want an HTTPError with status 503 after 3 attempts", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestHTTPErrorTruncatesBody(t *testing.T) {
+	body := strings.Repeat("x", maxErrorBodyPreview+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	_, err := Get[testUser](context.Background(), New(), server.URL)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Get() error = %v, want an HTTPError", err)
+	}
+	if want := body[:maxErrorBodyPreview] + "...(truncated)"; httpErr.Body != want {
+		t.Errorf("HTTPError.Body has %d bytes, want the first %d bytes of the body, marked as truncated", len(httpErr.Body), maxErrorBodyPreview)
+	}
+}
+
+func TestPost(t *testing.T) {
+	var received testUser
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s with Content-Type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":2,"name":"Ada"}`))
+	}))
+	defer server.Close()
+
+	got, err := Post[testUser, testUser](context.Background(), New(), server.URL, testUser{Name: "Ada"})
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if received != (testUser{Name: "Ada"}) {
+		t.Errorf("received body = %+v, want %+v", received, testUser{Name: "Ada"})
+	}
+	if got != (testUser{ID: 2, Name: "Ada"}) {
+		t.Errorf("Post() = %+v, want %+v", got, testUser{ID: 2, Name: "Ada"})
+	}
+}
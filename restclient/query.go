@@ -0,0 +1,175 @@
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithQuery adds query parameters to the request URL, after those already present in it.
+func WithQuery(values url.Values) RequestOption {
+	return func(req *http.Request) {
+		query := req.URL.Query()
+		for key, vs := range values {
+			for _, v := range vs {
+				query.Add(key, v)
+			}
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+// EncodeQuery encodes v as query parameters, to be added to a request with WithQuery.
+// v may be url.Values, a map[string]string, a map[string][]string, or a struct or pointer to a struct whose exported fields
+// become parameters named after their url tag, their json tag or else their Go name, e.g.:
+//
+//	type ListParams struct {
+//		Region string   `url:"region,omitempty"`
+//		Page   int      `url:"page"`
+//		Tags   []string `url:"tag"`
+//	}
+//
+// Fields tagged "-" are skipped, omitempty skips zero values and the fields of embedded structs are promoted.
+// Strings, booleans, numbers, time.Time values (formatted as RFC 3339), fmt.Stringer values, pointers to any of them
+// and slices of any of them are supported; slices repeat their parameter and nil pointers are skipped.
+func EncodeQuery(v any) (url.Values, error) {
+	switch values := v.(type) {
+	case nil:
+		return url.Values{}, nil
+	case url.Values:
+		return values, nil
+	case map[string][]string:
+		return values, nil
+	case map[string]string:
+		query := make(url.Values, len(values))
+		for key, value := range values {
+			query.Set(key, value)
+		}
+		return query, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode %T as query parameters", v)
+	}
+
+	query := make(url.Values)
+	if err := encodeQueryStruct(query, rv); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// encodeQueryStruct adds the fields of the struct rv to query.
+func encodeQueryStruct(query url.Values, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !isEmbeddedStruct(field) {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("url")
+		if !ok {
+			tag = field.Tag.Get("json")
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		value := rv.Field(i)
+		if field.Anonymous && name == "" {
+			for value.Kind() == reflect.Pointer && !value.IsNil() {
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				if err := encodeQueryStruct(query, value); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			// only the fields of unexported embedded structs are encoded
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(opts, "omitempty") && value.IsZero() {
+			continue
+		}
+
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+			for j := 0; j < value.Len(); j++ {
+				if err := addQueryValue(query, name, value.Index(j)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := addQueryValue(query, name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isEmbeddedStruct reports whether field embeds a struct or a pointer to a struct,
+// whose exported fields are promoted even when the embedded type itself is unexported, as encoding/json does.
+func isEmbeddedStruct(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return field.Anonymous && t.Kind() == reflect.Struct
+}
+
+// addQueryValue adds a single value to query under name.
+func addQueryValue(query url.Values, name string, value reflect.Value) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		query.Add(name, v.Format(time.RFC3339))
+		return nil
+	case fmt.Stringer:
+		query.Add(name, v.String())
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		query.Add(name, value.String())
+	case reflect.Bool:
+		query.Add(name, strconv.FormatBool(value.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		query.Add(name, strconv.FormatInt(value.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		query.Add(name, strconv.FormatUint(value.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		query.Add(name, strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()))
+	default:
+		return fmt.Errorf("query parameter %q: cannot encode %s", name, value.Type())
+	}
+	return nil
+}
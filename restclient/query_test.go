@@ -0,0 +1,140 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testRegion string
+
+func (r testRegion) String() string { return "region-" + string(r) }
+
+type testPaging struct {
+	Page int `url:"page"`
+	Size int `url:"size,omitempty"`
+}
+
+type testListParams struct {
+	testPaging
+	*testSorting
+	Region   testRegion `url:"region,omitempty"`
+	Query    string     `json:"q,omitempty"`
+	Tags     []string   `url:"tag"`
+	IDs      []*int     `url:"id"`
+	Active   *bool      `url:"active"`
+	Min      *float64   `url:"min,omitempty"`
+	Since    time.Time  `url:"since,omitempty"`
+	Internal string     `url:"-"`
+	Raw      string
+	secret   string
+}
+
+type testSorting struct {
+	Sort string `url:"sort"`
+}
+
+func TestEncodeQuery(t *testing.T) {
+	active, one, two := false, 1, 2
+
+	tests := []struct {
+		name    string
+		value   any
+		want    url.Values
+		wantErr bool
+	}{
+		{name: "nil", value: nil, want: url.Values{}},
+		{name: "values", value: url.Values{"a": {"1", "2"}}, want: url.Values{"a": {"1", "2"}}},
+		{name: "string map", value: map[string]string{"a": "1"}, want: url.Values{"a": {"1"}}},
+		{name: "nil struct pointer", value: (*testListParams)(nil), want: url.Values{}},
+		{
+			name:  "zero struct",
+			value: testListParams{},
+			want:  url.Values{"page": {"0"}, "Raw": {""}},
+		},
+		{
+			name: "struct pointer",
+			value: &testListParams{
+				testPaging:  testPaging{Page: 2, Size: 20},
+				testSorting: &testSorting{Sort: "-price"},
+				Region:      "west",
+				Query:       "red shoes",
+				Tags:        []string{"new", "sale"},
+				IDs:         []*int{&one, nil, &two},
+				Active:      &active,
+				Since:       time.Date(2024, 3, 1, 13, 0, 0, 0, time.FixedZone("WAT", 60*60)),
+				Internal:    "x",
+				Raw:         "y",
+				secret:      "z",
+			},
+			want: url.Values{
+				"page":   {"2"},
+				"size":   {"20"},
+				"sort":   {"-price"},
+				"region": {"region-west"},
+				"q":      {"red shoes"},
+				"tag":    {"new", "sale"},
+				"id":     {"1", "2"},
+				"active": {"false"},
+				"since":  {"2024-03-01T13:00:00+01:00"},
+				"Raw":    {"y"},
+			},
+		},
+		{
+			name: "unexported embedded struct with a name",
+			value: struct {
+				testPaging `url:"paging"`
+				*testSorting
+				Raw string `url:"raw"`
+			}{testPaging: testPaging{Page: 1}, Raw: "x"},
+			want: url.Values{"raw": {"x"}},
+		},
+		{name: "not a struct", value: []string{"a"}, wantErr: true},
+		{name: "unsupported field", value: struct{ M map[string]int }{M: map[string]int{"a": 1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeQuery(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EncodeQuery() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EncodeQuery() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodeQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithQuery(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+	}))
+	defer server.Close()
+
+	query, err := EncodeQuery(testListParams{testPaging: testPaging{Page: 1}, Tags: []string{"a b", "c&d"}})
+	if err != nil {
+		t.Fatalf("EncodeQuery() error = %v", err)
+	}
+	resp, err := New().Do(context.Background(), http.MethodGet, nil, server.URL+"?tag=first", WithQuery(query))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	want := url.Values{"page": {"1"}, "tag": {"first", "a b", "c&d"}, "Raw": {""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("received query = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// IdempotencyKeyHeader is the header marking a request as safe to retry whatever its method, see NonIdempotentRetry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// NonIdempotentRetry tells whether requests with a non-idempotent method, POST and PATCH, may be retried.
+// Retrying them may repeat their side effects when a failed attempt did reach the server.
+type NonIdempotentRetry int
+
+const (
+	// RetryWithIdempotencyKey retries non-idempotent requests only when they carry an Idempotency-Key header,
+	// letting the server recognize repeated attempts.
+	RetryWithIdempotencyKey NonIdempotentRetry = iota
+	// RetryNever never retries non-idempotent requests.
+	RetryNever
+	// RetryAlways retries non-idempotent requests like any other.
+	RetryAlways
+)
+
+// DefaultRetryableStatuses are the response statuses retried when a RetryPolicy does not list its own.
+var DefaultRetryableStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures how DoRetryable retries failed requests.
+//
+// Fields:
+//   - MaxAttempts: the number of times a request is sent at most, including the first attempt
+//   - BaseDelay: the delay before the first retry, doubled before every further retry; negative to retry immediately
+//   - MaxDelay: the longest delay between two attempts; negative for no limit. Responses asking with a Retry-After header
+//     to wait longer are not retried
+//   - Jitter: whether delays are drawn at random between 0 and their computed value ("full jitter"),
+//     which spreads the retries of many clients failing at the same time
+//   - RetryableStatuses: the response statuses that are retried; DefaultRetryableStatuses when nil
+//   - RetryableError: reports whether a transport error is retried; every error is retried when nil.
+//     Errors of the request context and requests refused by an open circuit breaker are never retried.
+//   - NonIdempotent: whether POST and PATCH requests may be retried, RetryWithIdempotencyKey by default
+//   - Sleep: waits between attempts, returning early with an error when the context is done; a timer when nil
+//   - Random: returns a number in [0, 1) used for jitter; math/rand/v2.Float64 when nil
+//   - Now: returns the current time, used to read Retry-After dates; time.Now when nil
+//
+// Sleep, Random and Now let tests run without waiting and with predictable delays.
+// A zero BaseDelay or MaxDelay takes its value from DefaultRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts       int
+	BaseDelay         time.Duration
+	MaxDelay          time.Duration
+	Jitter            bool
+	RetryableStatuses []int
+	RetryableError    func(err error) bool
+	NonIdempotent     NonIdempotentRetry
+	Sleep             func(ctx context.Context, d time.Duration) error
+	Random            func() float64
+	Now               func() time.Time
+}
+
+// DefaultRetryPolicy sends a request up to 6 times, waiting a random delay of up to 200ms before the first retry,
+// doubled for every further retry and capped at 5s. Non-idempotent requests are only retried with an Idempotency-Key.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 6,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      true,
+}
+
+// NoRetry sends every request once.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// newRetryPolicy returns the retry policy of a client, filling the zero delays with their default.
+func newRetryPolicy(policy RetryPolicy) RetryPolicy {
+	defaults := DefaultRetryPolicy
+	if policy.BaseDelay == 0 {
+		policy.BaseDelay = defaults.BaseDelay
+	}
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = defaults.MaxDelay
+	}
+	return policy
+}
+
+// allowsRetry reports whether req may be sent again, given its method.
+func (p RetryPolicy) allowsRetry(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPatch:
+		switch p.NonIdempotent {
+		case RetryAlways:
+			return true
+		case RetryNever:
+			return false
+		default:
+			return req.Header.Get(IdempotencyKeyHeader) != ""
+		}
+	default:
+		return true
+	}
+}
+
+// retryableStatus reports whether a response with the given status is retried.
+func (p RetryPolicy) retryableStatus(status int) bool {
+	statuses := p.RetryableStatuses
+	if statuses == nil {
+		statuses = DefaultRetryableStatuses
+	}
+	return slices.Contains(statuses, status)
+}
+
+// retryableError reports whether a transport error is retried.
+func (p RetryPolicy) retryableError(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	return p.RetryableError == nil || p.RetryableError(err)
+}
+
+// delay returns the time to wait before the given retry, counted from 1, honouring the Retry-After header of resp if any.
+// It reports false when the header asks to wait longer than the maximum delay, as retrying earlier would only
+// add to the load of the server.
+func (p RetryPolicy) delay(retry int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), p.now()); ok {
+			if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+				return 0, false
+			}
+			return retryAfter, true
+		}
+	}
+
+	d := max(p.BaseDelay, 0)
+	for i := 1; i < retry && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	d = p.capDelay(d)
+
+	if p.Jitter {
+		random := rand.Float64
+		if p.Random != nil {
+			random = p.Random
+		}
+		d = time.Duration(random() * float64(d))
+	}
+
+	return d, true
+}
+
+// now returns the current time.
+func (p RetryPolicy) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+// capDelay limits d to the maximum delay of the policy.
+func (p RetryPolicy) capDelay(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// sleep waits for d or until ctx is done.
+func (p RetryPolicy) sleep(ctx context.Context, d time.Duration) error {
+	if p.Sleep != nil {
+		return p.Sleep(ctx, d)
+	}
+	return wait(ctx, d)
+}
@@ -0,0 +1,250 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+		Now:       func() time.Time { return now },
+	}
+	withRetryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{value}}}
+	}
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		retry   int
+		resp    *http.Response
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "first retry", policy: policy, retry: 1, want: 100 * time.Millisecond},
+		{name: "doubled", policy: policy, retry: 3, want: 400 * time.Millisecond},
+		{name: "capped", policy: policy, retry: 10, want: time.Second},
+		{name: "retry after seconds", policy: policy, retry: 1, resp: withRetryAfter("1"), want: time.Second},
+		{name: "retry after longer than the maximum delay", policy: policy, retry: 1, resp: withRetryAfter("120"), wantErr: true},
+		{name: "retry after without limit", policy: RetryPolicy{MaxDelay: -1}, retry: 1, resp: withRetryAfter("120"), want: 2 * time.Minute},
+		{name: "retry after date", policy: policy, retry: 1, resp: withRetryAfter(now.Add(time.Second).Format(http.TimeFormat)), want: time.Second},
+		{name: "invalid retry after", policy: policy, retry: 2, resp: withRetryAfter("soon"), want: 200 * time.Millisecond},
+		{name: "no limit", policy: RetryPolicy{BaseDelay: time.Second, MaxDelay: -1}, retry: 8, want: 128 * time.Second},
+		{name: "immediate", policy: RetryPolicy{BaseDelay: -1, MaxDelay: time.Second}, retry: 3, want: 0},
+		{
+			name:   "jitter",
+			policy: RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: true, Random: func() float64 { return 0.5 }},
+			retry:  2,
+			want:   100 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.policy.delay(tt.retry, tt.resp)
+			if ok == tt.wantErr || got != tt.want {
+				t.Errorf("delay(%d) = %v, %t, want %v, %t", tt.retry, got, ok, tt.want, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRetryPolicyDefaultsDelays(t *testing.T) {
+	c := New(WithRetryPolicy(RetryPolicy{MaxAttempts: 4}))
+
+	if c.retryPolicy.BaseDelay != DefaultRetryPolicy.BaseDelay || c.retryPolicy.MaxDelay != DefaultRetryPolicy.MaxDelay {
+		t.Errorf("retry policy delays = %v, %v, want the delays of DefaultRetryPolicy", c.retryPolicy.BaseDelay, c.retryPolicy.MaxDelay)
+	}
+	if c.retryPolicy.MaxAttempts != 4 {
+		t.Errorf("retry policy MaxAttempts = %d, want 4", c.retryPolicy.MaxAttempts)
+	}
+}
+
+// testRetryServer responds with the given statuses in turn, then with 200 OK, counting the requests it receives.
+// 429 responses ask to retry after retryAfter.
+func testRetryServer(t *testing.T, retryAfter string, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			if statuses[n-1] == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// testRetryPolicy returns a policy recording its delays instead of waiting.
+func testRetryPolicy(maxAttempts int, delays *[]time.Duration) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			*delays = append(*delays, d)
+			return nil
+		},
+	}
+}
+
+func TestDoRetryable(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxAttempts  int
+		method       string
+		options      []RequestOption
+		retryAfter   string
+		wantRequests int32
+		wantDelays   []time.Duration
+		wantStatus   int
+	}{
+		{
+			name:         "succeeds after retryable statuses",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			maxAttempts:  4,
+			method:       http.MethodGet,
+			wantRequests: 3,
+			wantDelays:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:         "retry after",
+			statuses:     []int{http.StatusTooManyRequests},
+			maxAttempts:  2,
+			method:       http.MethodGet,
+			retryAfter:   "3",
+			wantRequests: 2,
+			wantDelays:   []time.Duration{3 * time.Second},
+		},
+		{
+			name:         "retry after longer than the maximum delay",
+			statuses:     []int{http.StatusTooManyRequests},
+			maxAttempts:  5,
+			method:       http.MethodGet,
+			retryAfter:   "60",
+			wantRequests: 1,
+			wantStatus:   http.StatusTooManyRequests,
+		},
+		{
+			name:         "gives up after the last attempt",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			maxAttempts:  3,
+			method:       http.MethodGet,
+			wantRequests: 3,
+			wantDelays:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:         "status not retryable",
+			statuses:     []int{http.StatusBadRequest},
+			maxAttempts:  3,
+			method:       http.MethodGet,
+			wantRequests: 1,
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "post without an idempotency key",
+			statuses:     []int{http.StatusServiceUnavailable},
+			maxAttempts:  3,
+			method:       http.MethodPost,
+			wantRequests: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:         "post with an idempotency key",
+			statuses:     []int{http.StatusServiceUnavailable},
+			maxAttempts:  3,
+			method:       http.MethodPost,
+			options:      []RequestOption{WithIdempotencyKey("order-1")},
+			wantRequests: 2,
+			wantDelays:   []time.Duration{100 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := testRetryServer(t, tt.retryAfter, tt.statuses...)
+			var delays []time.Duration
+			c := New(WithRetryPolicy(testRetryPolicy(tt.maxAttempts, &delays)))
+
+			resp, err := c.DoRetryable(context.Background(), tt.method, map[string]string{"id": "1"}, server.URL, tt.options...)
+			if tt.wantStatus != 0 {
+				var httpErr *HTTPError
+				if !errors.As(err, &httpErr) || httpErr.StatusCode != tt.wantStatus {
+					t.Fatalf("DoRetryable() error = %v, want an HTTPError with status %d", err, tt.wantStatus)
+				}
+				if httpErr.Attempts != int(tt.wantRequests) {
+					t.Errorf("HTTPError.Attempts = %d, want %d", httpErr.Attempts, tt.wantRequests)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("DoRetryable() error = %v", err)
+				}
+				resp.Body.Close()
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if !reflect.DeepEqual(delays, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestDoRetryableStopsWhenCanceled(t *testing.T) {
+	server, requests := testRetryServer(t, "", http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{
+		MaxAttempts: 5,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			cancel()
+			return ctx.Err()
+		},
+	}
+	c := New(WithRetryPolicy(policy))
+
+	if _, err := c.DoRetryable(ctx, http.MethodGet, nil, server.URL); !errors.Is(err, context.Canceled) {
+		t.Errorf("DoRetryable() error = %v, want context.Canceled", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestDoRetryableGivesUpBeforeTheDeadline(t *testing.T) {
+	server, requests := testRetryServer(t, "3", http.StatusTooManyRequests)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var delays []time.Duration
+	c := New(WithRetryPolicy(testRetryPolicy(5, &delays)))
+
+	_, err := c.DoRetryable(ctx, http.MethodGet, nil, server.URL)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("DoRetryable() error = %v, want an HTTPError with status %d", err, http.StatusTooManyRequests)
+	}
+	if got := requests.Load(); got != 1 || len(delays) != 0 {
+		t.Errorf("requests = %d after waiting %v, want a single request", got, delays)
+	}
+}